@@ -26,6 +26,7 @@ import (
 	"skeleton-internship-backend/internal/scheduler"
 	"skeleton-internship-backend/internal/service"
 	"skeleton-internship-backend/internal/store"
+	"skeleton-internship-backend/internal/telemetry"
 	"skeleton-internship-backend/internal/timescaledb"
 )
 
@@ -51,7 +52,7 @@ import (
 // @tag.docs.description Detailed information about todo operations
 
 // @tag.name         health
-// @tag.description  API health check operations
+// @tag.description  Liveness and readiness checks
 
 // @securityDefinitions.apikey Bearer
 // @in header
@@ -73,30 +74,61 @@ func main() {
 			repository.NewRepository,
 			elasticsearch.NewElasticsearchLogRepository,
 			timescaledb.NewTimescaleMetricRepository,
+			timescaledb.NewTimescaleIssueRepository,
+			timescaledb.NewTimescaleApplicationRepository,
 			store.NewInMemoryConversationStore,
 			service.NewService,
 			service.NewLogQueryService,
 			service.NewMetricQueryService,
 			service.NewNLVService,
+			service.NewTemplateService,
+			service.NewIssueService,
+			service.NewApplicationService,
+			service.NewDeadLetterService,
 			service.NewGeminiLLMService,
+			service.NewHealthService,
 			controller.NewLogController,
 			controller.NewMetricController,
 			controller.NewNLVController,
+			controller.NewTemplateController,
+			controller.NewIssueController,
+			controller.NewApplicationController,
+			controller.NewProducerController,
+			controller.NewIngestController,
+			controller.NewDeadLetterController,
+			controller.NewTelemetryController,
+			controller.NewHealthController,
 			NewFileStateManager,
-			parser.NewMultilineCapableParser,
-			kafka.NewKafkaLogProducer,
-			kafka.NewKafkaLogConsumer,
+			parser.NewParserRegistry,
+			parser.NewTimestampResolver,
+			parser.NewTemplateMatcher,
+			parser.NewFieldExtractor,
+			parser.NewTemplateMiner,
+			kafka.NewSecurity,
+			kafka.NewEmbeddedQueue,
+			kafka.NewLogProducer,
+			kafka.NewLogConsumer,
+			kafka.NewKafkaDeadLetterQueue,
 			elasticsearch.NewElasticLogStore,
 			timescaledb.ProvideTimescaleDBPool,
+			timescaledb.NewIssueStore,
+			timescaledb.NewApplicationStore,
 			metrics.NewSparkLogExtractor,
+			telemetry.NewMetrics,
 			service.NewLogProducerService,
 			service.NewLogConsumerService,
+			service.NewSyslogService,
 		),
 		fx.Invoke(RegisterAPIRoutes,
-			RegisterScheduler,
+			func(lc fx.Lifecycle, cfg *config.Config, producerService service.LogProducerService) { // Invoker to start producer
+				startLogProducer(lc, &wg, cfg, producerService)
+			},
 			func(lc fx.Lifecycle, consumerService service.LogConsumerService) { // Invoker to start consumer
 				startLogConsumer(lc, &wg, consumerService)
 			},
+			func(lc fx.Lifecycle, cfg *config.Config, syslogService service.SyslogService) { // Invoker to start syslog listener
+				startSyslogListener(lc, &wg, cfg, syslogService)
+			},
 		),
 	)
 
@@ -155,6 +187,14 @@ func RegisterAPIRoutes(
 	logController *controller.LogController,
 	metricController *controller.MetricController,
 	nlvController *controller.NLVController,
+	templateController *controller.TemplateController,
+	issueController *controller.IssueController,
+	applicationController *controller.ApplicationController,
+	producerController *controller.ProducerController,
+	ingestController *controller.IngestController,
+	deadLetterController *controller.DeadLetterController,
+	telemetryController *controller.TelemetryController,
+	healthController *controller.HealthController,
 ) {
 	if logController != nil {
 		controller.RegisterLogRoutes(router, logController)
@@ -172,6 +212,46 @@ func RegisterAPIRoutes(
 	} else {
 		log.Warn().Msg("NLVController not provided")
 	}
+	if templateController != nil {
+		controller.RegisterTemplateRoutes(router, templateController)
+	} else {
+		log.Warn().Msg("TemplateController not provided")
+	}
+	if issueController != nil {
+		controller.RegisterIssueRoutes(router, issueController)
+	} else {
+		log.Warn().Msg("IssueController not provided")
+	}
+	if applicationController != nil {
+		controller.RegisterApplicationRoutes(router, applicationController)
+	} else {
+		log.Warn().Msg("ApplicationController not provided")
+	}
+	if producerController != nil {
+		controller.RegisterProducerRoutes(router, producerController)
+	} else {
+		log.Warn().Msg("ProducerController not provided")
+	}
+	if ingestController != nil {
+		controller.RegisterIngestRoutes(router, ingestController)
+	} else {
+		log.Warn().Msg("IngestController not provided")
+	}
+	if deadLetterController != nil {
+		controller.RegisterDeadLetterRoutes(router, deadLetterController)
+	} else {
+		log.Warn().Msg("DeadLetterController not provided")
+	}
+	if telemetryController != nil {
+		controller.RegisterTelemetryRoutes(router, telemetryController)
+	} else {
+		log.Warn().Msg("TelemetryController not provided")
+	}
+	if healthController != nil {
+		controller.RegisterHealthRoutes(router, healthController)
+	} else {
+		log.Warn().Msg("HealthController not provided")
+	}
 
 	server := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -206,6 +286,35 @@ func RegisterScheduler(lc fx.Lifecycle, cfg *config.Config, logProducerSvc servi
 	scheduler.NewScheduler(lc, cfg, logProducerSvc)
 }
 
+// startLogProducer either tails the log directory or schedules periodic runs, depending on LOG_PROCESSOR_MODE
+func startLogProducer(lc fx.Lifecycle, wg *sync.WaitGroup, cfg *config.Config, producerService service.LogProducerService) {
+	switch cfg.LogProcessor.Mode {
+	case "tail":
+	case "poll", "":
+		RegisterScheduler(lc, cfg, producerService)
+		return
+	default:
+		log.Warn().Str("mode", cfg.LogProcessor.Mode).Msg("Unknown log processor mode, falling back to poll")
+		RegisterScheduler(lc, cfg, producerService)
+		return
+	}
+
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info().Msg("Starting Log Tailer goroutine")
+			go producerService.Tail(ctx, wg)
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			log.Info().Msg("Signaling Log Tailer goroutine to stop...")
+			cancel()
+			return nil
+		},
+	})
+}
+
 // startLogConsumer starts the LogConsumerService in a goroutine managed by fx lifecycle
 func startLogConsumer(lc fx.Lifecycle, wg *sync.WaitGroup, consumerService service.LogConsumerService) {
 	wg.Add(1)
@@ -224,3 +333,25 @@ func startLogConsumer(lc fx.Lifecycle, wg *sync.WaitGroup, consumerService servi
 		},
 	})
 }
+
+// startSyslogListener starts the SyslogService when a TCP or UDP address is configured
+func startSyslogListener(lc fx.Lifecycle, wg *sync.WaitGroup, cfg *config.Config, syslogService service.SyslogService) {
+	if cfg.Syslog.TCPAddress == "" && cfg.Syslog.UDPAddress == "" {
+		return
+	}
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info().Msg("Starting Syslog Listener goroutine")
+			go syslogService.Run(ctx, wg)
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			log.Info().Msg("Signaling Syslog Listener goroutine to stop...")
+			cancel()
+			return nil
+		},
+	})
+}
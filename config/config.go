@@ -13,32 +13,98 @@ type Config struct {
 	Database      DatabaseConfig
 	Kafka         KafkaConfig
 	LogProcessor  LogProcessorConfig
+	Syslog        SyslogConfig
 	Elasticsearch ElasticsearchConfig
 	TimescaleDB   TimescaleDBConfig
 	FileState     FileStateConfig
-	APIKey        string
+	APIKey        string `json:"-"` // Credentials are left out of the config logged at startup
 }
 
 type ServerConfig struct {
-	Port string
+	Port             string
+	ReadinessTimeout time.Duration // How long GET /readyz waits for each dependency
 }
 type KafkaConfig struct {
+	Backend       string // "kafka", or "embedded" for the on-disk queue in QueueDirectory, without brokers
 	Brokers       []string
 	LogTopic      string
 	ConsumerGroup string
+
+	// "json" (version 1) or "compact" (version 2). Consumers read both, but those older than version 2 only
+	// read json: switch to compact once every consumer of the log topic is upgraded.
+	MessageEncoding string
+	Compression     string // Writer compression: none, gzip, snappy, lz4 or zstd
+
+	SecurityProtocol string // PLAINTEXT, SSL, SASL_PLAINTEXT or SASL_SSL
+	SASLMechanism    string // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	SASLUsername     string
+	SASLPassword     string `json:"-"`
+	TLSCAFile        string // PEM bundle of the CAs trusted for the brokers; the system pool if empty
+	TLSCertFile      string // PEM client certificate for mTLS, with TLSKeyFile
+	TLSKeyFile       string
+
+	DeadLetterTopic     string // Where the consumer moves messages it gave up on; empty retries rejected log entries forever
+	MaxDeliveryAttempts int    // Attempts at storing log entries a store rejects before they are dead-lettered
+
+	QueueDirectory    string // Where the embedded backend keeps its write-ahead log
+	QueuePartitions   int    // Partitions of the embedded queue, consumed concurrently; never reduced for an existing queue
+	QueueSegmentBytes int64  // Size of the embedded queue's segment files, deleted once all their messages are committed
 }
 
 type LogProcessorConfig struct {
-	LogDirectory string // Root directory containing application_* folders
+	LogDirectory string      // Root directory containing application_* folders, used when no Sources are configured
+	Sources      []LogSource // Directory trees scanned for log files
+	Mode         string      // "poll" runs on Schedule, "tail" watches the Sources for changes
 	Schedule     string
 	BatchSize    int
 	MaxBatchWait time.Duration
+	Workers      int    // Files read concurrently in a run
+	TemplateFile string // CSV of known event templates (event_id,template)
+
+	TailDebounce         time.Duration // How long tail mode collects change notifications before reading the files
+	MultilineIdleTimeout time.Duration // An entry at the end of a file is only sent once the file has not changed for this long
+
+	IngestMaxBodyBytes int64         // Largest request body POST /api/v1/ingest accepts
+	IngestMaxInFlight  int           // Ingest requests processed at once; more are rejected with 429
+	IngestTimeout      time.Duration // How long an ingest request waits for Kafka before failing with 503
+
+	LearnedTemplateFile string  // JSON file where templates discovered online are persisted
+	DrainDepth          int     // Depth of the Drain parse tree (including root and length layers)
+	DrainSimThreshold   float64 // Minimum similarity for a message to join an existing template
+	DrainMaxChildren    int     // Maximum children per internal node before falling back to <*>
+
+	DefaultFormat string            // Parser for files matched by no rule: auto, spark, yarn, log4j2 or json
+	FormatRules   map[string]string // Directory glob (matched on base name or full path) -> parser name
+
+	Timezone      string            // IANA zone for timestamps written without an offset, e.g. "Asia/Ho_Chi_Minh"
+	TimezoneRules map[string]string // Directory glob -> IANA zone, overrides Timezone per source
+}
+
+// LogSource is a directory tree scanned for log files. Globs match either the path relative to Root
+// or the base name, like FormatRules.
+type LogSource struct {
+	Name               string
+	Root               string
+	Include            []string // Files to read; empty for the flat layout: archives in Root and logs in its application_* folders
+	Exclude            []string // Files and directories to skip
+	Recursive          bool     // Descend into every subdirectory instead of only Root's immediate ones
+	ApplicationPattern string   // Regex on the path relative to Root whose first group (or whole match) is the application ID
+}
+
+type SyslogConfig struct {
+	TCPAddress     string // e.g. ":5514"; empty disables the TCP listener
+	UDPAddress     string // e.g. ":5514"; empty disables the UDP listener
+	Application    string // Application syslog messages are attributed to
+	MaxMessageSize int    // Longer messages are truncated (UDP) or close the connection (TCP)
+
+	TCPIdleTimeout    time.Duration // A TCP connection is closed once a message takes longer than this to arrive
+	TCPMaxConnections int           // Further TCP connections are refused while this many are open
 }
 
 type ElasticsearchConfig struct {
 	Addresses     []string
 	Username      string
-	Password      string
+	Password      string `json:"-"`
 	LogIndex      string
 	BulkWorkers   int           // Number of concurrent goroutines for bulk indexing
 	FlushBytes    int           // Flush threshold for bulk indexer
@@ -46,7 +112,7 @@ type ElasticsearchConfig struct {
 }
 
 type TimescaleDBConfig struct {
-	DSN string
+	DSN string `json:"-"` // Holds the password
 }
 type FileStateConfig struct {
 	FilePath string
@@ -56,7 +122,7 @@ type DatabaseConfig struct {
 	Host     string
 	Port     string
 	User     string
-	Password string
+	Password string `json:"-"`
 	Name     string
 }
 
@@ -70,13 +136,47 @@ func NewConfig() (*Config, error) {
 	viper.AutomaticEnv()
 
 	viper.SetDefault("SERVER_PORT", "8080")
+	viper.SetDefault("SERVER_READINESS_TIMEOUT", "3s")
+	viper.SetDefault("KAFKA_BACKEND", "kafka")
 	viper.SetDefault("KAFKA_BROKERS", "localhost:9092")
 	viper.SetDefault("KAFKA_LOG_TOPIC", "log_entries")
 	viper.SetDefault("KAFKA_CONSUMER_GROUP", "log_processor_group")
+	viper.SetDefault("KAFKA_MESSAGE_ENCODING", "json") // Set to compact once every consumer is upgraded
+	viper.SetDefault("KAFKA_COMPRESSION", "zstd")
+	viper.SetDefault("KAFKA_SECURITY_PROTOCOL", "PLAINTEXT")
+	viper.SetDefault("KAFKA_SASL_MECHANISM", "SCRAM-SHA-512")
+	viper.SetDefault("KAFKA_DEAD_LETTER_TOPIC", "log_entries_dlq")
+	viper.SetDefault("KAFKA_MAX_DELIVERY_ATTEMPTS", 5)
+	viper.SetDefault("KAFKA_QUEUE_DIRECTORY", "./queue")
+	viper.SetDefault("KAFKA_QUEUE_PARTITIONS", 4)
+	viper.SetDefault("KAFKA_QUEUE_SEGMENT_BYTES", 67108864) // 64MB
 	viper.SetDefault("LOG_PROCESSOR_DIRECTORY", "./logs")
+	viper.SetDefault("LOG_PROCESSOR_SOURCES", "") // e.g. "flat,yarn", each configured by LOG_PROCESSOR_SOURCE_<NAME>_*
+	viper.SetDefault("LOG_PROCESSOR_MODE", "poll")
 	viper.SetDefault("LOG_PROCESSOR_SCHEDULE", "*/300 * * * * *") // Every 300 seconds
+	viper.SetDefault("LOG_PROCESSOR_TAIL_DEBOUNCE", "500ms")
+	viper.SetDefault("LOG_PROCESSOR_MULTILINE_IDLE_TIMEOUT", "10s")
+	viper.SetDefault("LOG_PROCESSOR_INGEST_MAX_BODY_BYTES", 10485760) // 10MB
+	viper.SetDefault("LOG_PROCESSOR_INGEST_MAX_IN_FLIGHT", 8)
+	viper.SetDefault("LOG_PROCESSOR_INGEST_TIMEOUT", "10s")
 	viper.SetDefault("LOG_PROCESSOR_BATCH_SIZE", 100)
 	viper.SetDefault("LOG_PROCESSOR_MAX_BATCH_WAIT", "5s")
+	viper.SetDefault("LOG_PROCESSOR_WORKERS", 4)
+	viper.SetDefault("LOG_PROCESSOR_TEMPLATE_FILE", "./event_templates.csv")
+	viper.SetDefault("LOG_PROCESSOR_LEARNED_TEMPLATE_FILE", "./learned_templates.json")
+	viper.SetDefault("LOG_PROCESSOR_DRAIN_DEPTH", 4)
+	viper.SetDefault("LOG_PROCESSOR_DRAIN_SIM_THRESHOLD", 0.4)
+	viper.SetDefault("LOG_PROCESSOR_DRAIN_MAX_CHILDREN", 100)
+	viper.SetDefault("LOG_PROCESSOR_DEFAULT_FORMAT", "auto")
+	viper.SetDefault("LOG_PROCESSOR_FORMAT_RULES", "") // e.g. "application_*=spark,nodemanager*=yarn"
+	viper.SetDefault("LOG_PROCESSOR_TIMEZONE", "UTC")
+	viper.SetDefault("LOG_PROCESSOR_TIMEZONE_RULES", "") // e.g. "application_*=Asia/Ho_Chi_Minh"
+	viper.SetDefault("SYSLOG_TCP_ADDRESS", "")
+	viper.SetDefault("SYSLOG_UDP_ADDRESS", "")
+	viper.SetDefault("SYSLOG_APPLICATION", "syslog")
+	viper.SetDefault("SYSLOG_MAX_MESSAGE_SIZE", 65536)
+	viper.SetDefault("SYSLOG_TCP_IDLE_TIMEOUT", "5m")
+	viper.SetDefault("SYSLOG_TCP_MAX_CONNECTIONS", 256)
 	viper.SetDefault("ELASTICSEARCH_ADDRESSES", "http://localhost:9200")
 	viper.SetDefault("ELASTICSEARCH_LOG_INDEX", "applogs")
 	viper.SetDefault("ELASTICSEARCH_BULK_WORKERS", 2)
@@ -92,6 +192,7 @@ func NewConfig() (*Config, error) {
 
 	var config Config
 	config.Server.Port = viper.GetString("SERVER_PORT")
+	config.Server.ReadinessTimeout = viper.GetDuration("SERVER_READINESS_TIMEOUT")
 
 	config.Database.Host = viper.GetString("DATABASE_HOST")
 	config.Database.Port = viper.GetString("DATABASE_PORT")
@@ -100,16 +201,56 @@ func NewConfig() (*Config, error) {
 	config.Database.Name = viper.GetString("DATABASE_NAME")
 
 	// --- Kafka ---
+	config.Kafka.Backend = strings.ToLower(viper.GetString("KAFKA_BACKEND"))
 	kafkaBrokers := viper.GetString("KAFKA_BROKERS")
 	config.Kafka.Brokers = strings.Split(kafkaBrokers, ",")
 	config.Kafka.LogTopic = viper.GetString("KAFKA_LOG_TOPIC")
 	config.Kafka.ConsumerGroup = viper.GetString("KAFKA_CONSUMER_GROUP")
+	config.Kafka.MessageEncoding = viper.GetString("KAFKA_MESSAGE_ENCODING")
+	config.Kafka.Compression = viper.GetString("KAFKA_COMPRESSION")
+	config.Kafka.SecurityProtocol = viper.GetString("KAFKA_SECURITY_PROTOCOL")
+	config.Kafka.SASLMechanism = viper.GetString("KAFKA_SASL_MECHANISM")
+	config.Kafka.SASLUsername = viper.GetString("KAFKA_SASL_USERNAME")
+	config.Kafka.SASLPassword = viper.GetString("KAFKA_SASL_PASSWORD")
+	config.Kafka.TLSCAFile = viper.GetString("KAFKA_TLS_CA_FILE")
+	config.Kafka.TLSCertFile = viper.GetString("KAFKA_TLS_CERT_FILE")
+	config.Kafka.TLSKeyFile = viper.GetString("KAFKA_TLS_KEY_FILE")
+	config.Kafka.DeadLetterTopic = viper.GetString("KAFKA_DEAD_LETTER_TOPIC")
+	config.Kafka.MaxDeliveryAttempts = viper.GetInt("KAFKA_MAX_DELIVERY_ATTEMPTS")
+	config.Kafka.QueueDirectory = viper.GetString("KAFKA_QUEUE_DIRECTORY")
+	config.Kafka.QueuePartitions = viper.GetInt("KAFKA_QUEUE_PARTITIONS")
+	config.Kafka.QueueSegmentBytes = viper.GetInt64("KAFKA_QUEUE_SEGMENT_BYTES")
 
 	// --- Log Processor ---
 	config.LogProcessor.LogDirectory = viper.GetString("LOG_PROCESSOR_DIRECTORY")
+	config.LogProcessor.Sources = loadLogSources(config.LogProcessor.LogDirectory)
+	config.LogProcessor.Mode = strings.ToLower(viper.GetString("LOG_PROCESSOR_MODE"))
 	config.LogProcessor.Schedule = viper.GetString("LOG_PROCESSOR_SCHEDULE")
+	config.LogProcessor.TailDebounce = viper.GetDuration("LOG_PROCESSOR_TAIL_DEBOUNCE")
+	config.LogProcessor.MultilineIdleTimeout = viper.GetDuration("LOG_PROCESSOR_MULTILINE_IDLE_TIMEOUT")
+	config.LogProcessor.IngestMaxBodyBytes = viper.GetInt64("LOG_PROCESSOR_INGEST_MAX_BODY_BYTES")
+	config.LogProcessor.IngestMaxInFlight = viper.GetInt("LOG_PROCESSOR_INGEST_MAX_IN_FLIGHT")
+	config.LogProcessor.IngestTimeout = viper.GetDuration("LOG_PROCESSOR_INGEST_TIMEOUT")
 	config.LogProcessor.BatchSize = viper.GetInt("LOG_PROCESSOR_BATCH_SIZE")
 	config.LogProcessor.MaxBatchWait = viper.GetDuration("LOG_PROCESSOR_MAX_BATCH_WAIT")
+	config.LogProcessor.Workers = viper.GetInt("LOG_PROCESSOR_WORKERS")
+	config.LogProcessor.TemplateFile = viper.GetString("LOG_PROCESSOR_TEMPLATE_FILE")
+	config.LogProcessor.LearnedTemplateFile = viper.GetString("LOG_PROCESSOR_LEARNED_TEMPLATE_FILE")
+	config.LogProcessor.DrainDepth = viper.GetInt("LOG_PROCESSOR_DRAIN_DEPTH")
+	config.LogProcessor.DrainSimThreshold = viper.GetFloat64("LOG_PROCESSOR_DRAIN_SIM_THRESHOLD")
+	config.LogProcessor.DrainMaxChildren = viper.GetInt("LOG_PROCESSOR_DRAIN_MAX_CHILDREN")
+	config.LogProcessor.DefaultFormat = viper.GetString("LOG_PROCESSOR_DEFAULT_FORMAT")
+	config.LogProcessor.FormatRules = parseKeyValueList(viper.GetString("LOG_PROCESSOR_FORMAT_RULES"))
+	config.LogProcessor.Timezone = viper.GetString("LOG_PROCESSOR_TIMEZONE")
+	config.LogProcessor.TimezoneRules = parseKeyValueList(viper.GetString("LOG_PROCESSOR_TIMEZONE_RULES"))
+
+	// --- Syslog ---
+	config.Syslog.TCPAddress = viper.GetString("SYSLOG_TCP_ADDRESS")
+	config.Syslog.UDPAddress = viper.GetString("SYSLOG_UDP_ADDRESS")
+	config.Syslog.Application = viper.GetString("SYSLOG_APPLICATION")
+	config.Syslog.MaxMessageSize = viper.GetInt("SYSLOG_MAX_MESSAGE_SIZE")
+	config.Syslog.TCPIdleTimeout = viper.GetDuration("SYSLOG_TCP_IDLE_TIMEOUT")
+	config.Syslog.TCPMaxConnections = viper.GetInt("SYSLOG_TCP_MAX_CONNECTIONS")
 
 	// --- Elasticsearch ---
 	esAddresses := viper.GetString("ELASTICSEARCH_ADDRESSES")
@@ -130,3 +271,60 @@ func NewConfig() (*Config, error) {
 	log.Info().Interface("config", config).Msg("Config loaded")
 	return &config, nil
 }
+
+// loadLogSources reads the sources named in LOG_PROCESSOR_SOURCES, each from its own variables:
+//
+//	LOG_PROCESSOR_SOURCE_YARN_ROOT=/var/log/hadoop-yarn/userlogs
+//	LOG_PROCESSOR_SOURCE_YARN_INCLUDE=stdout,stderr,syslog
+//	LOG_PROCESSOR_SOURCE_YARN_EXCLUDE=prelaunch.*
+//	LOG_PROCESSOR_SOURCE_YARN_RECURSIVE=true
+//	LOG_PROCESSOR_SOURCE_YARN_APPLICATION_PATTERN=^(application_\d+_\d+)/
+//
+// Without any, defaultRoot is read with the flat layout.
+func loadLogSources(defaultRoot string) []LogSource {
+	names := parseList(viper.GetString("LOG_PROCESSOR_SOURCES"))
+	if len(names) == 0 {
+		return []LogSource{{Name: "default", Root: defaultRoot}}
+	}
+	sources := make([]LogSource, 0, len(names))
+	for _, name := range names {
+		prefix := "LOG_PROCESSOR_SOURCE_" + strings.ToUpper(name) + "_"
+		sources = append(sources, LogSource{
+			Name:               name,
+			Root:               viper.GetString(prefix + "ROOT"),
+			Include:            parseList(viper.GetString(prefix + "INCLUDE")),
+			Exclude:            parseList(viper.GetString(prefix + "EXCLUDE")),
+			Recursive:          viper.GetBool(prefix + "RECURSIVE"),
+			ApplicationPattern: viper.GetString(prefix + "APPLICATION_PATTERN"),
+		})
+	}
+	return sources
+}
+
+// parseList parses "a,b,c", dropping blanks.
+func parseList(raw string) []string {
+	var result []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseKeyValueList parses "k1=v1,k2=v2" into a map, skipping malformed pairs.
+func parseKeyValueList(raw string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			if strings.TrimSpace(pair) != "" {
+				log.Warn().Str("pair", pair).Msg("Ignoring malformed key=value config entry")
+			}
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
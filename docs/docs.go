@@ -24,6 +24,566 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/applications": {
+            "get": {
+                "description": "Retrieves the applications active within a time range with their lifecycle status, start/end times and exit code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "List Spark applications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start time (ISO 8601 or epoch ms)",
+                        "name": "startTime",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (ISO 8601 or epoch ms)",
+                        "name": "endTime",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "RUNNING",
+                            "SUCCEEDED",
+                            "FAILED",
+                            "KILLED"
+                        ],
+                        "type": "string",
+                        "description": "Only applications with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of applications per page (default: 50, max: 1000)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved applications",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApplicationSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/applications/{id}": {
+            "get": {
+                "description": "Retrieves an application's lifecycle: attempts, containers with hosts and exit statuses, final status and exit code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Get a Spark application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Application ID (e.g., application_1485248649253_0186)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved application",
+                        "schema": {
+                            "$ref": "#/definitions/model.Application"
+                        }
+                    },
+                    "404": {
+                        "description": "Application not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/deadletters": {
+            "get": {
+                "description": "Retrieves the newest messages the log consumer moved to the dead-letter topic, either because they are not log entries or because storing them kept failing, with the error and where they came from.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "List dead letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only letters of this dead-letter topic partition",
+                        "name": "partition",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "With partition, only letters below this offset, to page through older ones",
+                        "name": "beforeOffset",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of letters to return (default: 50, max: 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved dead letters",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeadLetterListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Dead-letter topic is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/deadletters/replay": {
+            "post": {
+                "description": "Writes the original messages of the given dead letters back to the log topic so the consumer processes them again. The letters stay in the dead-letter topic.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Replay dead letters",
+                "parameters": [
+                    {
+                        "description": "IDs of the dead letters to replay (max 500)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeadLetterReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dead letters replayed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeadLetterReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead letter not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Dead-letter topic is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/deadletters/{id}": {
+            "get": {
+                "description": "Retrieves one dead letter with its original message.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Get a dead letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead letter ID (\u003cpartition\u003e-\u003coffset\u003e)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved dead letter",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeadLetter"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead letter not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Dead-letter topic is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ingest": {
+            "post": {
+                "description": "Parses raw log text, or NDJSON with one JSON log line per row, the same way as log files (multiline entries, templates, fields) and sends the entries to Kafka. Responds once Kafka has acknowledged every entry. Retry a failed request with the same X-Request-ID so entries already delivered are not duplicated.",
+                "consumes": [
+                    "text/plain",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingest"
+                ],
+                "summary": "Ingest log lines",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Application the lines belong to (e.g., application_1485248649253_0186)",
+                        "name": "X-Application-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "YARN container the lines belong to",
+                        "name": "X-Container-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Where the lines come from, shown as the source file (default: http)",
+                        "name": "X-Log-Source",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "spark",
+                            "yarn",
+                            "log4j2",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Parser to use; detected from the lines if omitted",
+                        "name": "X-Log-Format",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Idempotency key, generated if omitted",
+                        "name": "X-Request-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log lines",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All entries delivered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IngestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many ingest requests in flight, retry later",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Kafka did not acknowledge every entry in time, retry later",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.IngestResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/issues": {
+            "get": {
+                "description": "Retrieves error groups (issues) keyed by exception fingerprint, with first/last seen times, occurrence counts and affected applications.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "issues"
+                ],
+                "summary": "List error issues",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only issues last seen at or after this time (ISO 8601 or epoch ms)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only issues first seen at or before this time (ISO 8601 or epoch ms)",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list of application IDs; issues affecting any of them are returned",
+                        "name": "applications",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive search on title and exception class",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "last_seen",
+                            "first_seen",
+                            "count"
+                        ],
+                        "type": "string",
+                        "description": "Field to sort by (default: last_seen)",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order (asc or desc, default: desc)",
+                        "name": "sortOrder",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of issues per page (default: 50, max: 1000)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved issues",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IssueListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/issues/{id}": {
+            "get": {
+                "description": "Retrieves a single issue with its sample raw log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "issues"
+                ],
+                "summary": "Get an error issue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Issue ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved issue",
+                        "schema": {
+                            "$ref": "#/definitions/model.Issue"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Issue not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/logs": {
             "get": {
                 "description": "Retrieves logs based on specified time range, search query, levels, and applications. Supports pagination and sorting.",
@@ -70,6 +630,12 @@ const docTemplate = `{
                         "name": "applications",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated filters on extracted fields using :, \u003e, \u003e=, \u003c or \u003c= (e.g., executor_id:3,duration_ms\u003e=1000)",
+                        "name": "fields",
+                        "in": "query"
+                    },
                     {
                         "enum": [
                             "@timestamp",
@@ -130,57 +696,6 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/logs/applications": {
-            "get": {
-                "description": "Retrieves a list of unique application IDs found within a time range.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "logs"
-                ],
-                "summary": "Get distinct application IDs",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Start time (ISO 8601 or epoch ms)",
-                        "name": "startTime",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "End time (ISO 8601 or epoch ms)",
-                        "name": "endTime",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Successfully retrieved application list",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ApplicationListResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid query parameters",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    }
-                }
-            }
-        },
         "/api/v1/metrics/distribution": {
             "get": {
                 "description": "Retrieves the distribution of a metric (e.g., log_event count) grouped by a specified dimension (e.g., level, component) within a time range. Suitable for pie charts or bar charts showing proportions.",
@@ -231,10 +746,15 @@ const docTemplate = `{
                             "level",
                             "component",
                             "error_key",
+                            "event_id",
+                            "exception_class",
+                            "root_cause",
+                            "container_id",
+                            "attempt",
                             "application"
                         ],
                         "type": "string",
-                        "description": "Dimension to group by for distribution (e.g., level, component, error_key)",
+                        "description": "Dimension to group by for distribution (e.g., level, component, error_key, event_id, exception_class, container_id, attempt)",
                         "name": "dimension",
                         "in": "query",
                         "required": true
@@ -384,11 +904,16 @@ const docTemplate = `{
                             "level",
                             "component",
                             "error_key",
+                            "event_id",
+                            "exception_class",
+                            "root_cause",
+                            "container_id",
+                            "attempt",
                             "application",
                             "total"
                         ],
                         "type": "string",
-                        "description": "Tag key to group by (e.g., level, component, error_key, application, total)",
+                        "description": "Tag key to group by (e.g., level, component, error_key, event_id, exception_class, root_cause, container_id, attempt, application, total)",
                         "name": "groupBy",
                         "in": "query"
                     }
@@ -461,6 +986,73 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/producer/stats": {
+            "get": {
+                "description": "Retrieves how many lines and entries the log producer has read and delivered, the throughput of its last run and how many bytes of the log files are still waiting to be delivered.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "producer"
+                ],
+                "summary": "Get log producer statistics",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved producer statistics",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProducerStatsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/templates": {
+            "get": {
+                "description": "Retrieves the known event templates from event_templates.csv and the templates learned online from unmatched log messages.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "List log event templates",
+                "parameters": [
+                    {
+                        "enum": [
+                            "static",
+                            "learned"
+                        ],
+                        "type": "string",
+                        "description": "Only return templates from this source",
+                        "name": "source",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved templates",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TemplateListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/todos": {
             "get": {
                 "description": "get all todos",
@@ -722,35 +1314,129 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/healthz": {
             "get": {
-                "description": "get the status of server.",
-                "consumes": [
-                    "*/*"
-                ],
+                "description": "Tells that the server is running and serving requests. It checks no dependency, so a failing dependency does not get the process restarted.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "health"
                 ],
-                "summary": "Show the status of server.",
+                "summary": "Liveness check",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Server is alive",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
             }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Exports the pipeline's metrics in the Prometheus text format: consumer lag per partition, what the producer read per cycle, Elasticsearch bulk indexing, TimescaleDB CopyFrom latency and NLV/LLM call latencies.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "telemetry"
+                ],
+                "summary": "Get pipeline metrics",
+                "responses": {
+                    "200": {
+                        "description": "Metrics in the Prometheus exposition format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Pings Elasticsearch, TimescaleDB, the Kafka brokers (or the embedded queue), the LLM endpoint and the directory of the state file concurrently, and reports the status and latency of each. The LLM result is reused for 60 seconds, so probes do not each call the LLM API. The status is \"degraded\" when only optional dependencies (the LLM) are down, and \"unavailable\" when a required one is.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "Every required dependency is up",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "A required dependency is down",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReadinessResponse"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
-        "dto.ApplicationListResponse": {
+        "dto.ApplicationSearchResponse": {
             "type": "object",
             "properties": {
                 "applications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Application"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "totalCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DeadLetterListResponse": {
+            "type": "object",
+            "properties": {
+                "deadLetters": {
+                    "description": "Newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeadLetter"
+                    }
+                },
+                "totalCount": {
+                    "description": "Letters held by the selected partitions",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DeadLetterReplayRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.DeadLetterReplayResponse": {
+            "type": "object",
+            "properties": {
+                "replayed": {
+                    "description": "IDs of the letters written back to the log topic",
                     "type": "array",
                     "items": {
                         "type": "string"
@@ -758,6 +1444,28 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "description": "Whether the service is unavailable without it",
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "up or down",
+                    "type": "string"
+                }
+            }
+        },
         "dto.DistributionDataPoint": {
             "type": "object",
             "properties": {
@@ -765,8 +1473,47 @@ const docTemplate = `{
                     "description": "Tên của phần (ví dụ: \"INFO\", \"ERROR\", \"YarnAllocator\")",
                     "type": "string"
                 },
-                "value": {
-                    "description": "Giá trị đếm",
+                "value": {
+                    "description": "Giá trị đếm",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.IngestResponse": {
+            "type": "object",
+            "properties": {
+                "delivered": {
+                    "description": "Entries acknowledged by Kafka",
+                    "type": "integer"
+                },
+                "entries": {
+                    "description": "Log entries assembled from the lines",
+                    "type": "integer"
+                },
+                "linesRead": {
+                    "type": "integer"
+                },
+                "requestId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.IssueListResponse": {
+            "type": "object",
+            "properties": {
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Issue"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "totalCount": {
                     "type": "integer"
                 }
             }
@@ -926,6 +1673,47 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ProducerStatsResponse": {
+            "type": "object",
+            "properties": {
+                "entriesFailed": {
+                    "type": "integer"
+                },
+                "entriesSent": {
+                    "type": "integer"
+                },
+                "failedRuns": {
+                    "description": "Runs stopped by a failed delivery to Kafka",
+                    "type": "integer"
+                },
+                "filesProcessed": {
+                    "type": "integer"
+                },
+                "lagBytes": {
+                    "description": "Bytes written to plain log files but not yet delivered",
+                    "type": "integer"
+                },
+                "laggingFiles": {
+                    "description": "Plain log files with undelivered bytes",
+                    "type": "integer"
+                },
+                "lastRunAt": {
+                    "type": "string"
+                },
+                "lastRunDurationMs": {
+                    "type": "integer"
+                },
+                "lastRunEntriesPerSecond": {
+                    "type": "number"
+                },
+                "linesRead": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.QueryFilter": {
             "type": "object",
             "properties": {
@@ -942,6 +1730,21 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "description": "ok, degraded or unavailable",
+                    "type": "string"
+                }
+            }
+        },
         "dto.SortInfo": {
             "type": "object",
             "properties": {
@@ -953,6 +1756,36 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.TemplateInfo": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Messages assigned so far, learned templates only",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "\"static\" (event_templates.csv) | \"learned\" (Drain miner)",
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TemplateListResponse": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TemplateInfo"
+                    }
+                }
+            }
+        },
         "dto.TimeRange": {
             "type": "object",
             "properties": {
@@ -1022,6 +1855,227 @@ const docTemplate = `{
                 }
             }
         },
+        "model.Application": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Only filled in when fetching a single application",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ApplicationAttempt"
+                    }
+                },
+                "container_count": {
+                    "type": "integer"
+                },
+                "containers": {
+                    "description": "Only filled in when fetching a single application",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ContainerInfo"
+                    }
+                },
+                "diagnostics": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "description": "When the final status was logged",
+                    "type": "string"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "first_seen": {
+                    "description": "Earliest lifecycle log line, also for apps without ApplicationMaster logs",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "description": "First ApplicationMaster attempt start",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ApplicationAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "attempt_id": {
+                    "description": "e.g. appattempt_1485248649253_0186_000002",
+                    "type": "string"
+                },
+                "diagnostics": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ContainerInfo": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "container_id": {
+                    "type": "string"
+                },
+                "diagnostics": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "exit_status": {
+                    "type": "integer"
+                },
+                "first_seen": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "launch_time": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "\"\u003cpartition\u003e-\u003coffset\u003e\" in the dead-letter topic",
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "originalOffset": {
+                    "type": "integer"
+                },
+                "originalPartition": {
+                    "type": "integer"
+                },
+                "originalTopic": {
+                    "type": "string"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "description": "DeadLetterStageDecode or DeadLetterStageStore",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "The original message, unchanged",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ExceptionInfo": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "frames": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.StackFrame"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "omitted_frames": {
+                    "description": "From \"... N more\"",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Issue": {
+            "type": "object",
+            "properties": {
+                "applications": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "component": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "exception_class": {
+                    "type": "string"
+                },
+                "fingerprint": {
+                    "type": "string"
+                },
+                "first_seen": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "sample_raw_log": {
+                    "description": "raw_log of the most recent occurrence",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
         "model.LogEntry": {
             "type": "object",
             "properties": {
@@ -1031,12 +2085,41 @@ const docTemplate = `{
                 "application": {
                     "type": "string"
                 },
+                "attempt": {
+                    "description": "Application attempt the container belongs to",
+                    "type": "integer"
+                },
                 "component": {
                     "type": "string"
                 },
+                "container_id": {
+                    "type": "string"
+                },
                 "content": {
                     "type": "string"
                 },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_params": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fields": {
+                    "description": "Typed values extracted from Content, e.g. executor_id, duration_ms",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "hostname": {
+                    "description": "Sending host of syslog messages",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Derived from the source file and offset, stable across redeliveries",
+                    "type": "string"
+                },
                 "level": {
                     "type": "string"
                 },
@@ -1045,6 +2128,13 @@ const docTemplate = `{
                 },
                 "source_file": {
                     "type": "string"
+                },
+                "stack_trace": {
+                    "$ref": "#/definitions/model.StackTrace"
+                },
+                "timestamp_inferred": {
+                    "description": "Timestamp was inherited, not parsed from the line",
+                    "type": "boolean"
                 }
             }
         },
@@ -1057,6 +2147,37 @@ const docTemplate = `{
                 }
             }
         },
+        "model.StackFrame": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "method": {
+                    "description": "Fully qualified, e.g. org.apache.spark.executor.Executor$TaskRunner.run",
+                    "type": "string"
+                }
+            }
+        },
+        "model.StackTrace": {
+            "type": "object",
+            "properties": {
+                "exceptions": {
+                    "description": "Outermost exception first, followed by each \"Caused by\"",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExceptionInfo"
+                    }
+                },
+                "fingerprint": {
+                    "description": "Stable across hosts and builds: line numbers, hex addresses and messages are ignored",
+                    "type": "string"
+                }
+            }
+        },
         "model.Todo": {
             "description": "Todo represents a single todo item with its details",
             "type": "object",
@@ -1117,7 +2238,7 @@ const docTemplate = `{
             }
         },
         {
-            "description": "API health check operations",
+            "description": "Liveness and readiness checks",
             "name": "health"
         }
     ]
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+	"skeleton-internship-backend/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ApplicationController struct {
+	applicationService service.ApplicationService
+}
+
+func NewApplicationController(applicationService service.ApplicationService) *ApplicationController {
+	return &ApplicationController{
+		applicationService: applicationService,
+	}
+}
+
+func RegisterApplicationRoutes(router *gin.Engine, controller *ApplicationController) {
+	v1 := router.Group("/api/v1/applications")
+	{
+		v1.GET("", controller.GetApplications)
+		v1.GET("/:id", controller.GetApplication)
+	}
+}
+
+// GetApplications godoc
+// @Summary      List Spark applications
+// @Description  Retrieves the applications active within a time range with their lifecycle status, start/end times and exit code.
+// @Tags         applications
+// @Accept       json
+// @Produce      json
+// @Param        startTime    query     string  true   "Start time (ISO 8601 or epoch ms)"
+// @Param        endTime      query     string  true   "End time (ISO 8601 or epoch ms)"
+// @Param        status       query     string  false  "Only applications with this status" Enums(RUNNING, SUCCEEDED, FAILED, KILLED)
+// @Param        page         query     int     false  "Page number (default: 1)" minimum(1)
+// @Param        size         query     int     false  "Number of applications per page (default: 50, max: 1000)" minimum(1) maximum(1000)
+// @Success      200          {object}  dto.ApplicationSearchResponse "Successfully retrieved applications"
+// @Failure      400          {object}  model.Response "Invalid query parameters"
+// @Failure      500          {object}  model.Response "Internal server error"
+// @Router       /api/v1/applications [get]
+func (c *ApplicationController) GetApplications(ctx *gin.Context) {
+	startTime, endTime, _, err := parseBaseQueryParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "50"))
+	if err != nil || size <= 0 || size > 1000 {
+		size = 50
+	}
+
+	req := dto.ApplicationSearchRequest{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    strings.TrimSpace(ctx.Query("status")),
+		Page:      page,
+		Size:      size,
+	}
+
+	result, err := c.applicationService.ListApplications(ctx.Request.Context(), req)
+	if err != nil {
+		log.Error().Err(err).Msg("Error listing applications")
+		if strings.Contains(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to list applications", nil))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetApplication godoc
+// @Summary      Get a Spark application
+// @Description  Retrieves an application's lifecycle: attempts, containers with hosts and exit statuses, final status and exit code.
+// @Tags         applications
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Application ID (e.g., application_1485248649253_0186)"
+// @Success      200  {object}  model.Application "Successfully retrieved application"
+// @Failure      404  {object}  model.Response "Application not found"
+// @Failure      500  {object}  model.Response "Internal server error"
+// @Router       /api/v1/applications/{id} [get]
+func (c *ApplicationController) GetApplication(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	app, err := c.applicationService.GetApplication(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrApplicationNotFound) {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Application not found", nil))
+			return
+		}
+		log.Error().Err(err).Str("application", id).Msg("Error getting application")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to get application", nil))
+		return
+	}
+	ctx.JSON(http.StatusOK, app)
+}
@@ -9,7 +9,6 @@ import (
 	"skeleton-internship-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 )
 
 type Controller struct {
@@ -23,7 +22,6 @@ func NewController(service service.Service) *Controller {
 }
 
 func (c *Controller) RegisterRoutes(router *gin.Engine) {
-	router.GET("/health", c.HealthCheck)
 	v1 := router.Group("/api/v1")
 	{
 		todos := v1.Group("/todos")
@@ -37,19 +35,6 @@ func (c *Controller) RegisterRoutes(router *gin.Engine) {
 	}
 }
 
-// HealthCheck godoc
-// @Summary Show the status of server.
-// @Description get the status of server.
-// @Tags health
-// @Accept */*
-// @Produce json
-// @Success 200 {object} model.Response
-// @Router /health [get]
-func (x *Controller) HealthCheck(ctx *gin.Context) {
-	log.Info().Msg("Health check")
-	ctx.JSON(http.StatusOK, model.NewResponse("OK", nil))
-}
-
 // GetAllTodos godoc
 // @Summary Get all todos
 // @Description get all todos
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/kafka"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type DeadLetterController struct {
+	deadLetterService service.DeadLetterService
+}
+
+func NewDeadLetterController(deadLetterService service.DeadLetterService) *DeadLetterController {
+	return &DeadLetterController{
+		deadLetterService: deadLetterService,
+	}
+}
+
+func RegisterDeadLetterRoutes(router *gin.Engine, controller *DeadLetterController) {
+	v1 := router.Group("/api/v1/deadletters")
+	{
+		v1.GET("", controller.GetDeadLetters)
+		v1.GET("/:id", controller.GetDeadLetter)
+		v1.POST("/replay", controller.ReplayDeadLetters)
+	}
+}
+
+// GetDeadLetters godoc
+// @Summary      List dead letters
+// @Description  Retrieves the newest messages the log consumer moved to the dead-letter topic, either because they are not log entries or because storing them kept failing, with the error and where they came from.
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        partition     query     int     false  "Only letters of this dead-letter topic partition"
+// @Param        beforeOffset  query     int     false  "With partition, only letters below this offset, to page through older ones"
+// @Param        limit         query     int     false  "Number of letters to return (default: 50, max: 500)" minimum(1) maximum(500)
+// @Success      200           {object}  dto.DeadLetterListResponse "Successfully retrieved dead letters"
+// @Failure      400           {object}  model.Response "Invalid query parameters"
+// @Failure      503           {object}  model.Response "Dead-letter topic is not configured"
+// @Failure      500           {object}  model.Response "Internal server error"
+// @Router       /api/v1/deadletters [get]
+func (c *DeadLetterController) GetDeadLetters(ctx *gin.Context) {
+	req := dto.DeadLetterListRequest{Partition: -1}
+	if s := ctx.Query("partition"); s != "" {
+		partition, err := strconv.Atoi(s)
+		if err != nil || partition < 0 {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid partition", nil))
+			return
+		}
+		req.Partition = partition
+	}
+	if s := ctx.Query("beforeOffset"); s != "" {
+		offset, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || offset < 0 {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid beforeOffset", nil))
+			return
+		}
+		req.BeforeOffset = offset
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	req.Limit = limit
+
+	result, err := c.deadLetterService.ListDeadLetters(ctx.Request.Context(), req)
+	if err != nil {
+		c.respondError(ctx, err, "Failed to list dead letters")
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetDeadLetter godoc
+// @Summary      Get a dead letter
+// @Description  Retrieves one dead letter with its original message.
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Dead letter ID (<partition>-<offset>)"
+// @Success      200  {object}  model.DeadLetter "Successfully retrieved dead letter"
+// @Failure      400  {object}  model.Response "Invalid ID format"
+// @Failure      404  {object}  model.Response "Dead letter not found"
+// @Failure      503  {object}  model.Response "Dead-letter topic is not configured"
+// @Failure      500  {object}  model.Response "Internal server error"
+// @Router       /api/v1/deadletters/{id} [get]
+func (c *DeadLetterController) GetDeadLetter(ctx *gin.Context) {
+	letter, err := c.deadLetterService.GetDeadLetter(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to get dead letter")
+		return
+	}
+	ctx.JSON(http.StatusOK, letter)
+}
+
+// ReplayDeadLetters godoc
+// @Summary      Replay dead letters
+// @Description  Writes the original messages of the given dead letters back to the log topic so the consumer processes them again. The letters stay in the dead-letter topic.
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.DeadLetterReplayRequest  true  "IDs of the dead letters to replay (max 500)"
+// @Success      200      {object}  dto.DeadLetterReplayResponse "Dead letters replayed"
+// @Failure      400      {object}  model.Response "Invalid request"
+// @Failure      404      {object}  model.Response "Dead letter not found"
+// @Failure      503      {object}  model.Response "Dead-letter topic is not configured"
+// @Failure      500      {object}  model.Response "Internal server error"
+// @Router       /api/v1/deadletters/replay [post]
+func (c *DeadLetterController) ReplayDeadLetters(ctx *gin.Context) {
+	var req dto.DeadLetterReplayRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid request body: "+err.Error(), nil))
+		return
+	}
+
+	result, err := c.deadLetterService.ReplayDeadLetters(ctx.Request.Context(), req)
+	if err != nil {
+		c.respondError(ctx, err, "Failed to replay dead letters")
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *DeadLetterController) respondError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, kafka.ErrDeadLetterDisabled):
+		ctx.JSON(http.StatusServiceUnavailable, model.NewResponse("Dead-letter topic is not configured", nil))
+	case errors.Is(err, kafka.ErrDeadLetterNotFound):
+		ctx.JSON(http.StatusNotFound, model.NewResponse(err.Error(), nil))
+	case strings.Contains(err.Error(), "invalid"):
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse(message, nil))
+	}
+}
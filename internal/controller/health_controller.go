@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService service.HealthService
+}
+
+func NewHealthController(healthService service.HealthService) *HealthController {
+	return &HealthController{
+		healthService: healthService,
+	}
+}
+
+func RegisterHealthRoutes(router *gin.Engine, controller *HealthController) {
+	router.GET("/healthz", controller.Liveness)
+	router.GET("/readyz", controller.Readiness)
+}
+
+// Liveness godoc
+// @Summary      Liveness check
+// @Description  Tells that the server is running and serving requests. It checks no dependency, so a failing dependency does not get the process restarted.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  model.Response "Server is alive"
+// @Router       /healthz [get]
+func (c *HealthController) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, model.NewResponse("OK", nil))
+}
+
+// Readiness godoc
+// @Summary      Readiness check
+// @Description  Pings Elasticsearch, TimescaleDB, the Kafka brokers (or the embedded queue), the LLM endpoint and the directory of the state file concurrently, and reports the status and latency of each. The LLM result is reused for 60 seconds, so probes do not each call the LLM API. The status is "degraded" when only optional dependencies (the LLM) are down, and "unavailable" when a required one is.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  dto.ReadinessResponse "Every required dependency is up"
+// @Failure      503  {object}  dto.ReadinessResponse "A required dependency is down"
+// @Router       /readyz [get]
+func (c *HealthController) Readiness(ctx *gin.Context) {
+	result := c.healthService.Ready(ctx.Request.Context())
+	status := http.StatusOK
+	if result.Status == dto.ReadinessUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, result)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+	"skeleton-internship-backend/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ingestRetryAfter is the Retry-After, in seconds, sent when ingestion is throttled or Kafka is unavailable.
+const ingestRetryAfter = 5
+
+type IngestController struct {
+	producerService service.LogProducerService
+	maxBodyBytes    int64
+}
+
+func NewIngestController(producerService service.LogProducerService, cfg *config.Config) *IngestController {
+	return &IngestController{
+		producerService: producerService,
+		maxBodyBytes:    cfg.LogProcessor.IngestMaxBodyBytes,
+	}
+}
+
+func RegisterIngestRoutes(router *gin.Engine, controller *IngestController) {
+	v1 := router.Group("/api/v1/ingest")
+	{
+		v1.POST("", controller.Ingest)
+	}
+}
+
+// Ingest godoc
+// @Summary      Ingest log lines
+// @Description  Parses raw log text, or NDJSON with one JSON log line per row, the same way as log files (multiline entries, templates, fields) and sends the entries to Kafka. Responds once Kafka has acknowledged every entry. Retry a failed request with the same X-Request-ID so entries already delivered are not duplicated.
+// @Tags         ingest
+// @Accept       plain,application/x-ndjson
+// @Produce      json
+// @Param        X-Application-ID  header    string  true   "Application the lines belong to (e.g., application_1485248649253_0186)"
+// @Param        X-Container-ID    header    string  false  "YARN container the lines belong to"
+// @Param        X-Log-Source      header    string  false  "Where the lines come from, shown as the source file (default: http)"
+// @Param        X-Log-Format      header    string  false  "Parser to use; detected from the lines if omitted" Enums(spark, yarn, log4j2, json)
+// @Param        X-Request-ID      header    string  false  "Idempotency key, generated if omitted"
+// @Param        body              body      string  true   "Log lines"
+// @Success      200               {object}  dto.IngestResponse "All entries delivered"
+// @Failure      400               {object}  model.Response "Invalid request"
+// @Failure      413               {object}  model.Response "Request body too large"
+// @Failure      429               {object}  model.Response "Too many ingest requests in flight, retry later"
+// @Failure      503               {object}  model.Response{data=dto.IngestResponse} "Kafka did not acknowledge every entry in time, retry later"
+// @Failure      500               {object}  model.Response "Internal server error"
+// @Router       /api/v1/ingest [post]
+func (c *IngestController) Ingest(ctx *gin.Context) {
+	req := dto.IngestRequest{
+		Application: strings.TrimSpace(ctx.GetHeader("X-Application-ID")),
+		ContainerID: strings.TrimSpace(ctx.GetHeader("X-Container-ID")),
+		Source:      strings.TrimSpace(ctx.GetHeader("X-Log-Source")),
+		Format:      strings.ToLower(strings.TrimSpace(ctx.GetHeader("X-Log-Format"))),
+		RequestID:   strings.TrimSpace(ctx.GetHeader("X-Request-ID")),
+	}
+	if mediaType, _, err := mime.ParseMediaType(ctx.GetHeader("Content-Type")); err == nil && mediaType == "application/x-ndjson" && req.Format == "" {
+		req.Format = parser.FormatJSON
+	}
+	if ctx.Request.ContentLength > c.maxBodyBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, model.NewResponse("Request body too large", nil))
+		return
+	}
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxBodyBytes)
+
+	result, err := c.producerService.Ingest(ctx.Request.Context(), req, body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, model.NewResponse("Request body too large", nil))
+		case errors.Is(err, service.ErrIngestBusy):
+			ctx.Header("Retry-After", strconv.Itoa(ingestRetryAfter))
+			ctx.JSON(http.StatusTooManyRequests, model.NewResponse(err.Error(), nil))
+		case errors.Is(err, service.ErrIngestUnavailable):
+			ctx.Header("Retry-After", strconv.Itoa(ingestRetryAfter))
+			ctx.JSON(http.StatusServiceUnavailable, model.NewResponse("Log delivery to Kafka failed, retry later", result))
+		case strings.Contains(err.Error(), "invalid"):
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		default:
+			log.Error().Err(err).Str("application", req.Application).Msg("Error ingesting logs")
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to ingest logs", nil))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
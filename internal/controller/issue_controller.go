@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+	"skeleton-internship-backend/internal/service"
+	"skeleton-internship-backend/internal/util"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type IssueController struct {
+	issueService service.IssueService
+}
+
+func NewIssueController(issueService service.IssueService) *IssueController {
+	return &IssueController{
+		issueService: issueService,
+	}
+}
+
+func RegisterIssueRoutes(router *gin.Engine, controller *IssueController) {
+	v1 := router.Group("/api/v1/issues")
+	{
+		v1.GET("", controller.GetIssues)
+		v1.GET("/:id", controller.GetIssue)
+	}
+}
+
+// GetIssues godoc
+// @Summary      List error issues
+// @Description  Retrieves error groups (issues) keyed by exception fingerprint, with first/last seen times, occurrence counts and affected applications.
+// @Tags         issues
+// @Accept       json
+// @Produce      json
+// @Param        startTime    query     string  false  "Only issues last seen at or after this time (ISO 8601 or epoch ms)"
+// @Param        endTime      query     string  false  "Only issues first seen at or before this time (ISO 8601 or epoch ms)"
+// @Param        applications query     string  false  "Comma-separated list of application IDs; issues affecting any of them are returned"
+// @Param        query        query     string  false  "Case-insensitive search on title and exception class"
+// @Param        sortBy       query     string  false  "Field to sort by (default: last_seen)" Enums(last_seen, first_seen, count)
+// @Param        sortOrder    query     string  false  "Sort order (asc or desc, default: desc)" Enums(asc, desc)
+// @Param        page         query     int     false  "Page number (default: 1)" minimum(1)
+// @Param        size         query     int     false  "Number of issues per page (default: 50, max: 1000)" minimum(1) maximum(1000)
+// @Success      200          {object}  dto.IssueListResponse "Successfully retrieved issues"
+// @Failure      400          {object}  model.Response "Invalid query parameters"
+// @Failure      500          {object}  model.Response "Internal server error"
+// @Router       /api/v1/issues [get]
+func (c *IssueController) GetIssues(ctx *gin.Context) {
+	var startTime, endTime time.Time
+	var err error
+	if s := ctx.Query("startTime"); s != "" {
+		if startTime, err = util.ParseTimeFlexible(s); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid startTime format. Use ISO 8601 or epoch milliseconds.", nil))
+			return
+		}
+	}
+	if s := ctx.Query("endTime"); s != "" {
+		if endTime, err = util.ParseTimeFlexible(s); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid endTime format. Use ISO 8601 or epoch milliseconds.", nil))
+			return
+		}
+	}
+	var applications []string
+	if applicationsStr := ctx.Query("applications"); applicationsStr != "" {
+		applications = strings.Split(applicationsStr, ",")
+		for i := range applications {
+			applications[i] = strings.TrimSpace(applications[i])
+		}
+	}
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "50"))
+	if err != nil || size <= 0 || size > 1000 {
+		size = 50
+	}
+
+	req := dto.IssueListRequest{
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Applications: applications,
+		Query:        strings.TrimSpace(ctx.Query("query")),
+		SortBy:       ctx.DefaultQuery("sortBy", "last_seen"),
+		SortOrder:    ctx.DefaultQuery("sortOrder", "desc"),
+		Page:         page,
+		Size:         size,
+	}
+
+	result, err := c.issueService.ListIssues(ctx.Request.Context(), req)
+	if err != nil {
+		log.Error().Err(err).Msg("Error listing issues")
+		if strings.Contains(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to list issues", nil))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetIssue godoc
+// @Summary      Get an error issue
+// @Description  Retrieves a single issue with its sample raw log.
+// @Tags         issues
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Issue ID"
+// @Success      200  {object}  model.Issue "Successfully retrieved issue"
+// @Failure      400  {object}  model.Response "Invalid ID format"
+// @Failure      404  {object}  model.Response "Issue not found"
+// @Failure      500  {object}  model.Response "Internal server error"
+// @Router       /api/v1/issues/{id} [get]
+func (c *IssueController) GetIssue(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	issue, err := c.issueService.GetIssue(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrIssueNotFound) {
+			ctx.JSON(http.StatusNotFound, model.NewResponse("Issue not found", nil))
+			return
+		}
+		log.Error().Err(err).Int64("issue_id", id).Msg("Error getting issue")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to get issue", nil))
+		return
+	}
+	ctx.JSON(http.StatusOK, issue)
+}
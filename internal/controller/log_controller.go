@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
 	"skeleton-internship-backend/internal/dto"
 	"skeleton-internship-backend/internal/model"
@@ -41,6 +42,7 @@ func RegisterLogRoutes(router *gin.Engine, controller *LogController) {
 // @Param        query        query     string  false  "Free text search query"
 // @Param        levels       query     string  false  "Comma-separated list of log levels (e.g., ERROR,WARN)"
 // @Param        applications query     string  false  "Comma-separated list of application IDs (e.g., application_123,app_456)"
+// @Param        fields       query     string  false  "Comma-separated filters on extracted fields using :, >, >=, < or <= (e.g., executor_id:3,duration_ms>=1000)"
 // @Param        sortBy       query     string  false  "Field to sort by (default: @timestamp)" Enums(@timestamp, level, component, application)
 // @Param        sortOrder    query     string  false  "Sort order (asc or desc, default: desc)" Enums(asc, desc)
 // @Param        page         query     int     false  "Page number (default: 1)" minimum(1)
@@ -55,6 +57,7 @@ func (c *LogController) GetLogs(ctx *gin.Context) {
 	query := ctx.Query("query")
 	levelsStr := ctx.Query("levels")
 	applicationsStr := ctx.Query("applications")
+	fieldsStr := ctx.Query("fields")
 	sortBy := ctx.DefaultQuery("sortBy", "@timestamp")
 	sortOrder := ctx.DefaultQuery("sortOrder", "desc")
 	pageStr := ctx.DefaultQuery("page", "1")
@@ -81,6 +84,11 @@ func (c *LogController) GetLogs(ctx *gin.Context) {
 			applications[i] = strings.TrimSpace(applications[i])
 		}
 	}
+	fieldFilters, err := parseFieldFilters(fieldsStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
 		page = 1
@@ -95,6 +103,7 @@ func (c *LogController) GetLogs(ctx *gin.Context) {
 		Query:        query,
 		Levels:       levels,
 		Applications: applications,
+		FieldFilters: fieldFilters,
 		SortBy:       sortBy,
 		SortOrder:    sortOrder,
 		Page:         page,
@@ -104,10 +113,48 @@ func (c *LogController) GetLogs(ctx *gin.Context) {
 	result, err := c.logQueryService.SearchLogs(ctx.Request.Context(), searchReq)
 	if err != nil {
 		log.Error().Err(err).Msg("Error searching logs")
-		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to search logs", nil))
+		if strings.Contains(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to search logs", nil))
+		}
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 
 }
+
+// parseFieldFilters parses "executor_id:3,duration_ms>=1000" into field filters.
+func parseFieldFilters(raw string) ([]dto.LogFieldFilter, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var filters []dto.LogFieldFilter
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.IndexAny(part, ":<>")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid field filter %q, expected <field>:<value> or <field><op><value>", part)
+		}
+		op, rest := part[i:i+1], part[i+1:]
+		if op == ":" {
+			op = dto.FieldOpEq
+		} else if strings.HasPrefix(rest, "=") {
+			op, rest = op+"=", rest[1:]
+		}
+		value := strings.TrimSpace(rest)
+		if value == "" {
+			return nil, fmt.Errorf("invalid field filter %q, missing value", part)
+		}
+		filters = append(filters, dto.LogFieldFilter{
+			Field:    strings.TrimSpace(part[:i]),
+			Operator: op,
+			Value:    value,
+		})
+	}
+	return filters, nil
+}
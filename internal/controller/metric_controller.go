@@ -31,10 +31,6 @@ func RegisterMetricRoutes(router *gin.Engine, controller *MetricController) {
 		v1Metrics.GET("/timeseries", controller.GetTimeseriesMetrics)
 		v1Metrics.GET("/distribution", controller.GetDistributionMetrics)
 	}
-	v1Logs := router.Group("/api/v1/logs")
-	{
-		v1Logs.GET("/applications", controller.GetApplications)
-	}
 }
 
 // GetSummaryMetrics godoc
@@ -83,7 +79,7 @@ func (c *MetricController) GetSummaryMetrics(ctx *gin.Context) {
 // @Param        applications query     string  false  "Comma-separated list of application IDs"
 // @Param        metricName   query     string  true   "Metric name (e.g., log_event, error_event)" Enums(log_event, error_event)
 // @Param        interval     query     string  true   "Time interval for bucketing (e.g., '5 minute', '1 hour')" Enums(1 minute, 5 minute, 10 minute, 30 minute, 1 hour, 1 day)
-// @Param        groupBy      query     string  false  "Tag key to group by (e.g., level, component, error_key, application, total)" Enums(level, component, error_key, application, total)
+// @Param        groupBy      query     string  false  "Tag key to group by (e.g., level, component, error_key, event_id, exception_class, root_cause, container_id, attempt, application, total)" Enums(level, component, error_key, event_id, exception_class, root_cause, container_id, attempt, application, total)
 // @Success      200          {object}  dto.MetricTimeseriesResponse "Successfully retrieved timeseries metrics"
 // @Failure      400          {object}  model.Response "Invalid query parameters"
 // @Failure      500          {object}  model.Response "Internal server error"
@@ -131,39 +127,6 @@ func (c *MetricController) GetTimeseriesMetrics(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
-// GetApplications godoc
-// @Summary      Get distinct application IDs
-// @Description  Retrieves a list of unique application IDs found within a time range.
-// @Tags         logs
-// @Accept       json
-// @Produce      json
-// @Param        startTime    query     string  true   "Start time (ISO 8601 or epoch ms)"
-// @Param        endTime      query     string  true   "End time (ISO 8601 or epoch ms)"
-// @Success      200          {object}  dto.ApplicationListResponse "Successfully retrieved application list"
-// @Failure      400          {object}  model.Response "Invalid query parameters"
-// @Failure      500          {object}  model.Response "Internal server error"
-// @Router       /api/v1/logs/applications [get]
-func (c *MetricController) GetApplications(ctx *gin.Context) {
-	startTime, endTime, _, err := parseBaseQueryParams(ctx) // Không cần applications ở đây
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
-		return
-	}
-
-	req := dto.ApplicationListRequest{
-		StartTime: startTime,
-		EndTime:   endTime,
-	}
-
-	result, err := c.metricQueryService.GetApplications(ctx.Request.Context(), req)
-	if err != nil {
-		log.Error().Err(err).Msg("Error getting applications")
-		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to get applications", nil))
-		return
-	}
-	ctx.JSON(http.StatusOK, result)
-}
-
 func parseBaseQueryParams(ctx *gin.Context) (time.Time, time.Time, []string, error) {
 	startTimeStr := ctx.Query("startTime")
 	endTimeStr := ctx.Query("endTime")
@@ -202,7 +165,7 @@ func parseBaseQueryParams(ctx *gin.Context) (time.Time, time.Time, []string, err
 // @Param        endTime      query     string  true   "End time (ISO 8601 or epoch ms)"
 // @Param        applications query     string  false  "Comma-separated list of application IDs"
 // @Param        metricName   query     string  true   "Metric name (e.g., log_event, error_event)" Enums(log_event, error_event)
-// @Param        dimension    query     string  true   "Dimension to group by for distribution (e.g., level, component, error_key)" Enums(level, component, error_key, application)
+// @Param        dimension    query     string  true   "Dimension to group by for distribution (e.g., level, component, error_key, event_id, exception_class, container_id, attempt)" Enums(level, component, error_key, event_id, exception_class, root_cause, container_id, attempt, application)
 // @Success      200          {object}  dto.MetricDistributionResponse "Successfully retrieved metric distribution"
 // @Failure      400          {object}  model.Response "Invalid query parameters"
 // @Failure      500          {object}  model.Response "Internal server error"
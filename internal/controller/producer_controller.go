@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"net/http"
+	"skeleton-internship-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProducerController struct {
+	producerService service.LogProducerService
+}
+
+func NewProducerController(producerService service.LogProducerService) *ProducerController {
+	return &ProducerController{
+		producerService: producerService,
+	}
+}
+
+func RegisterProducerRoutes(router *gin.Engine, controller *ProducerController) {
+	v1 := router.Group("/api/v1/producer")
+	{
+		v1.GET("/stats", controller.GetStats)
+	}
+}
+
+// GetStats godoc
+// @Summary      Get log producer statistics
+// @Description  Retrieves how many lines and entries the log producer has read and delivered, the throughput of its last run and how many bytes of the log files are still waiting to be delivered.
+// @Tags         producer
+// @Produce      json
+// @Success      200  {object}  dto.ProducerStatsResponse "Successfully retrieved producer statistics"
+// @Router       /api/v1/producer/stats [get]
+func (c *ProducerController) GetStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.producerService.Stats())
+}
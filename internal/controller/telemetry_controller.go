@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+	"skeleton-internship-backend/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type TelemetryController struct {
+	handler http.Handler
+}
+
+func NewTelemetryController(metrics *telemetry.Metrics) *TelemetryController {
+	return &TelemetryController{
+		handler: promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}),
+	}
+}
+
+func RegisterTelemetryRoutes(router *gin.Engine, controller *TelemetryController) {
+	router.GET("/metrics", controller.GetMetrics)
+}
+
+// GetMetrics godoc
+// @Summary      Get pipeline metrics
+// @Description  Exports the pipeline's metrics in the Prometheus text format: consumer lag per partition, what the producer read per cycle, Elasticsearch bulk indexing, TimescaleDB CopyFrom latency and NLV/LLM call latencies.
+// @Tags         telemetry
+// @Produce      plain
+// @Success      200  {string}  string "Metrics in the Prometheus exposition format"
+// @Router       /metrics [get]
+func (c *TelemetryController) GetMetrics(ctx *gin.Context) {
+	c.handler.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type TemplateController struct {
+	templateService service.TemplateService
+}
+
+func NewTemplateController(templateService service.TemplateService) *TemplateController {
+	return &TemplateController{
+		templateService: templateService,
+	}
+}
+
+func RegisterTemplateRoutes(router *gin.Engine, controller *TemplateController) {
+	v1 := router.Group("/api/v1/templates")
+	{
+		v1.GET("", controller.GetTemplates)
+	}
+}
+
+// GetTemplates godoc
+// @Summary      List log event templates
+// @Description  Retrieves the known event templates from event_templates.csv and the templates learned online from unmatched log messages.
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        source  query     string  false  "Only return templates from this source" Enums(static, learned)
+// @Success      200     {object}  dto.TemplateListResponse "Successfully retrieved templates"
+// @Failure      400     {object}  model.Response "Invalid query parameters"
+// @Failure      500     {object}  model.Response "Internal server error"
+// @Router       /api/v1/templates [get]
+func (c *TemplateController) GetTemplates(ctx *gin.Context) {
+	source := strings.ToLower(strings.TrimSpace(ctx.Query("source")))
+
+	result, err := c.templateService.GetTemplates(ctx.Request.Context(), source)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting templates")
+		if strings.Contains(err.Error(), "invalid") {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to get templates", nil))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"skeleton-internship-backend/internal/model"
+	"time"
+)
+
+type ApplicationSearchRequest struct {
+	StartTime time.Time // Applications active at some point in [StartTime, EndTime]
+	EndTime   time.Time
+	Status    string // Optional, one of the model.AppStatus* values
+	Page      int
+	Size      int
+}
+
+type ApplicationSearchResponse struct {
+	Applications []model.Application `json:"applications"`
+	TotalCount   int64               `json:"totalCount"`
+	Page         int                 `json:"page"`
+	Size         int                 `json:"size"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "skeleton-internship-backend/internal/model"
+
+type DeadLetterListRequest struct {
+	Partition    int   // -1 for every partition
+	BeforeOffset int64 // With Partition, only letters below this offset, to page through older ones
+	Limit        int
+}
+
+type DeadLetterListResponse struct {
+	DeadLetters []model.DeadLetter `json:"deadLetters"` // Newest first
+	TotalCount  int64              `json:"totalCount"`  // Letters held by the selected partitions
+}
+
+type DeadLetterReplayRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+type DeadLetterReplayResponse struct {
+	Replayed []string `json:"replayed"` // IDs of the letters written back to the log topic
+}
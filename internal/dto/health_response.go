@@ -0,0 +1,23 @@
+package dto
+
+const (
+	ReadinessOK          = "ok"          // Every dependency is up
+	ReadinessDegraded    = "degraded"    // Only optional dependencies are down
+	ReadinessUnavailable = "unavailable" // A required dependency is down
+
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+type ReadinessResponse struct {
+	Status       string             `json:"status"` // ok, degraded or unavailable
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`   // up or down
+	Required  bool    `json:"required"` // Whether the service is unavailable without it
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+type IngestRequest struct {
+	Application string // Required; the application the lines are attributed to
+	ContainerID string // Optional YARN container ID
+	Source      string // Where the lines come from, e.g. "ci/build-42"; shown as the source file
+	Format      string // Parser to use; detected from the lines if empty
+	RequestID   string // Retries of a request must reuse its ID; generated if empty
+}
+
+type IngestResponse struct {
+	RequestID string `json:"requestId"`
+	LinesRead int64  `json:"linesRead"`
+	Entries   int    `json:"entries"`   // Log entries assembled from the lines
+	Delivered int    `json:"delivered"` // Entries acknowledged by Kafka
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"skeleton-internship-backend/internal/model"
+	"time"
+)
+
+type IssueListRequest struct {
+	StartTime    time.Time // Optional, issues last seen at or after
+	EndTime      time.Time // Optional, issues first seen at or before
+	Applications []string
+	Query        string // Case-insensitive match on title and exception class
+	SortBy       string // "last_seen", "first_seen" or "count"
+	SortOrder    string
+	Page         int
+	Size         int
+}
+
+type IssueListResponse struct {
+	Issues     []model.Issue `json:"issues"`
+	TotalCount int64         `json:"totalCount"`
+	Page       int           `json:"page"`
+	Size       int           `json:"size"`
+}
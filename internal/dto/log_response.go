@@ -11,12 +11,29 @@ type LogSearchRequest struct {
 	Query        string
 	Levels       []string
 	Applications []string
+	FieldFilters []LogFieldFilter
 	SortBy       string
 	SortOrder    string
 	Page         int
 	Size         int
 }
 
+// Field filter operators; FieldOpEq matches exactly, the others compare numerically.
+const (
+	FieldOpEq  = "="
+	FieldOpGt  = ">"
+	FieldOpGte = ">="
+	FieldOpLt  = "<"
+	FieldOpLte = "<="
+)
+
+// LogFieldFilter filters on one of the typed fields extracted from log content, e.g. duration_ms >= 1000.
+type LogFieldFilter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
 type LogSearchResponse struct {
 	Logs       []model.LogEntry `json:"logs"`
 	TotalCount int64            `json:"totalCount"`
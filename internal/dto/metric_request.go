@@ -19,11 +19,6 @@ type MetricTimeseriesRequest struct {
 	Limit        *int
 }
 
-type ApplicationListRequest struct {
-	StartTime time.Time
-	EndTime   time.Time
-}
-
 type MetricDistributionRequest struct {
 	StartTime    time.Time
 	EndTime      time.Time
@@ -22,11 +22,6 @@ type MetricTimeseriesResponse struct {
 	Series []TimeseriesSeries `json:"series"`
 }
 
-// ApplicationListResponse cấu trúc trả về cho API lấy danh sách application
-type ApplicationListResponse struct {
-	Applications []string `json:"applications"`
-}
-
 type DistributionDataPoint struct {
 	Name  string `json:"name"`  // Tên của phần (ví dụ: "INFO", "ERROR", "YarnAllocator")
 	Value int64  `json:"value"` // Giá trị đếm
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type ProducerStatsResponse struct {
+	Runs           int64 `json:"runs"`
+	FailedRuns     int64 `json:"failedRuns"` // Runs stopped by a failed delivery to Kafka
+	FilesProcessed int64 `json:"filesProcessed"`
+	LinesRead      int64 `json:"linesRead"`
+	EntriesSent    int64 `json:"entriesSent"`
+	EntriesFailed  int64 `json:"entriesFailed"`
+
+	LastRunAt               time.Time `json:"lastRunAt"`
+	LastRunDurationMs       int64     `json:"lastRunDurationMs"`
+	LastRunEntriesPerSecond float64   `json:"lastRunEntriesPerSecond"`
+
+	LagBytes     int64 `json:"lagBytes"`     // Bytes written to plain log files but not yet delivered
+	LaggingFiles int   `json:"laggingFiles"` // Plain log files with undelivered bytes
+}
@@ -0,0 +1,12 @@
+package dto
+
+type TemplateInfo struct {
+	EventID  string `json:"eventId"`
+	Template string `json:"template"`
+	Source   string `json:"source"`         // "static" (event_templates.csv) | "learned" (Drain miner)
+	Size     *int64 `json:"size,omitempty"` // Messages assigned so far, learned templates only
+}
+
+type TemplateListResponse struct {
+	Templates []TemplateInfo `json:"templates"`
+}
@@ -10,6 +10,7 @@ import (
 	"skeleton-internship-backend/internal/dto"
 	"skeleton-internship-backend/internal/model"
 	"skeleton-internship-backend/internal/repository"
+	"strconv"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -107,6 +108,10 @@ func (r *elasticsearchLogRepository) Search(ctx context.Context, req dto.LogSear
 		})
 	}
 
+	for _, f := range req.FieldFilters {
+		queryParts = append(queryParts, fieldFilterQuery(f))
+	}
+
 	from := (req.Page - 1) * req.Size
 	order := sortorder.Desc
 	if req.SortOrder == "asc" {
@@ -119,6 +124,7 @@ func (r *elasticsearchLogRepository) Search(ctx context.Context, req dto.LogSear
 			"component":   true,
 			"application": true,
 			"source_file": true,
+			"event_id":    true,
 		}
 		if knownKeywordFields[sortField] {
 			sortField = fmt.Sprintf("%s.keyword", req.SortBy)
@@ -177,3 +183,35 @@ func (r *elasticsearchLogRepository) Search(ctx context.Context, req dto.LogSear
 	log.Debug().Int64("total_hits", response.TotalCount).Int("returned_hits", len(response.Logs)).Msg("Elasticsearch search successful")
 	return response, nil
 }
+
+// fieldFilterQuery builds the query for a filter on the extracted fields object.
+// Values were validated by the service, so range filters always carry numbers.
+func fieldFilterQuery(f dto.LogFieldFilter) types.Query {
+	field := "fields." + f.Field
+	if f.Operator == dto.FieldOpEq {
+		return types.Query{
+			Term: map[string]types.TermQuery{
+				field: {Value: f.Value},
+			},
+		}
+	}
+
+	parsed, _ := strconv.ParseFloat(f.Value, 64)
+	value := types.Float64(parsed)
+	rangeQuery := types.NumberRangeQuery{}
+	switch f.Operator {
+	case dto.FieldOpGt:
+		rangeQuery.Gt = &value
+	case dto.FieldOpGte:
+		rangeQuery.Gte = &value
+	case dto.FieldOpLt:
+		rangeQuery.Lt = &value
+	case dto.FieldOpLte:
+		rangeQuery.Lte = &value
+	}
+	return types.Query{
+		Range: map[string]types.RangeQuery{
+			field: rangeQuery,
+		},
+	}
+}
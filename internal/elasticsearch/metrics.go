@@ -0,0 +1,62 @@
+package elasticsearch
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	documentsDesc = prometheus.NewDesc(
+		"datakat_elasticsearch_documents_total",
+		"Log entries Elasticsearch indexed or rejected, as reported to the BulkIndexer item callbacks.",
+		[]string{"result"}, nil,
+	)
+	bulkItemsDesc = prometheus.NewDesc(
+		"datakat_elasticsearch_bulk_items_total",
+		"Items of the BulkIndexer, by what happened to them.",
+		[]string{"state"}, nil,
+	)
+	bulkRequestsDesc = prometheus.NewDesc(
+		"datakat_elasticsearch_bulk_requests_total",
+		"Bulk requests the BulkIndexer sent.",
+		nil, nil,
+	)
+	bulkFlushedBytesDesc = prometheus.NewDesc(
+		"datakat_elasticsearch_bulk_flushed_bytes_total",
+		"Bytes the BulkIndexer flushed.",
+		nil, nil,
+	)
+)
+
+// storeCollector reads the store's counters and the BulkIndexer stats at scrape time.
+type storeCollector struct {
+	store *elasticLogStore
+}
+
+func (c storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- documentsDesc
+	ch <- bulkItemsDesc
+	ch <- bulkRequestsDesc
+	ch <- bulkFlushedBytesDesc
+}
+
+func (c storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(documentsDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&c.store.countSuccessful)), "successful")
+	ch <- prometheus.MustNewConstMetric(documentsDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&c.store.countFailed)), "failed")
+
+	stats := c.store.bulkStats()
+	for state, count := range map[string]uint64{
+		"added":   stats.NumAdded,
+		"flushed": stats.NumFlushed,
+		"failed":  stats.NumFailed,
+		"indexed": stats.NumIndexed,
+		"created": stats.NumCreated,
+		"updated": stats.NumUpdated,
+		"deleted": stats.NumDeleted,
+	} {
+		ch <- prometheus.MustNewConstMetric(bulkItemsDesc, prometheus.CounterValue, float64(count), state)
+	}
+	ch <- prometheus.MustNewConstMetric(bulkRequestsDesc, prometheus.CounterValue, float64(stats.NumRequests))
+	ch <- prometheus.MustNewConstMetric(bulkFlushedBytesDesc, prometheus.CounterValue, float64(stats.FlushedBytes))
+}
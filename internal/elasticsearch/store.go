@@ -10,11 +10,16 @@ import (
 	"net/http"
 	"skeleton-internship-backend/config"
 	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+	"skeleton-internship-backend/internal/telemetry"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff"
 	"github.com/elastic/go-elasticsearch"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
 	"github.com/elastic/go-elasticsearch/v8/esutil"
 	"github.com/rs/zerolog/log"
 	"go.uber.org/fx"
@@ -26,13 +31,15 @@ type LogStore interface {
 }
 type elasticLogStore struct {
 	client          *elasticsearch.Client
-	bulkIndexer     esutil.BulkIndexer
+	indexerConfig   esutil.BulkIndexerConfig // Of the BulkIndexer each StoreLogs call flushes
 	indexPrefix     string
+	statsMu         sync.Mutex
+	stats           esutil.BulkIndexerStats // Totals of the closed BulkIndexers
 	countSuccessful uint64
 	countFailed     uint64
 }
 
-func NewElasticLogStore(lc fx.Lifecycle, cfg *config.Config) (LogStore, *elasticsearch.Client, error) {
+func NewElasticLogStore(lc fx.Lifecycle, cfg *config.Config, fields parser.FieldExtractor, metrics *telemetry.Metrics) (LogStore, *elasticsearch.Client, error) {
 	if len(cfg.Elasticsearch.Addresses) == 0 {
 		log.Error().Msg("Elasticsearch addresses are not configured.")
 		return nil, nil, errors.New("elasticsearch configuration missing")
@@ -87,22 +94,20 @@ func NewElasticLogStore(lc fx.Lifecycle, cfg *config.Config) (LogStore, *elastic
 		log.Fatal().Err(err).Msg("Failed to connect to Elasticsearch after multiple retries")
 		return nil, nil, err
 	}
-	// Create BulkIndexer
 	store := &elasticLogStore{
 		client:      esClient,
 		indexPrefix: cfg.Elasticsearch.LogIndex,
 	}
+	if err := store.putIndexTemplate(context.Background(), fields.FieldTypes()); err != nil {
+		// Not fatal: extracted fields fall back to dynamic mapping
+		log.Warn().Err(err).Msg("Failed to put Elasticsearch index template for extracted fields")
+	}
 
-	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+	store.indexerConfig = esutil.BulkIndexerConfig{
 		Client:        esClient,
-		Index:         store.getIndexName(),            // Default index, can be overridden per item
 		NumWorkers:    cfg.Elasticsearch.BulkWorkers,   // Number of workers
 		FlushBytes:    cfg.Elasticsearch.FlushBytes,    // Flush threshold
-		FlushInterval: cfg.Elasticsearch.FlushInterval, // Flush interval
-		OnError: func(ctx context.Context, err error) {
-			log.Error().Err(err).Msg("BulkIndexer error")
-			// TODO: error handling/retry logic here
-		},
+		FlushInterval: cfg.Elasticsearch.FlushInterval, // Flush interval, items are flushed when StoreLogs returns anyway
 		OnFlushStart: func(ctx context.Context) context.Context {
 			log.Debug().Msg("BulkIndexer flush starting")
 			return ctx
@@ -110,17 +115,12 @@ func NewElasticLogStore(lc fx.Lifecycle, cfg *config.Config) (LogStore, *elastic
 		OnFlushEnd: func(ctx context.Context) {
 			log.Debug().Msg("BulkIndexer flush ended")
 		},
-	})
-	if err != nil {
-		log.Fatal().Err(err).Msg("Error creating the BulkIndexer")
-		return nil, nil, err
 	}
-	store.bulkIndexer = bi
-	log.Info().Msg("Elasticsearch BulkIndexer initialized")
+	metrics.Registry.MustRegister(storeCollector{store: store})
+	log.Info().Msg("Elasticsearch log store initialized")
 
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
-			log.Info().Msg("Closing Elasticsearch BulkIndexer...")
 			return store.Close(ctx)
 		},
 	})
@@ -128,53 +128,174 @@ func NewElasticLogStore(lc fx.Lifecycle, cfg *config.Config) (LogStore, *elastic
 	return store, esClient, nil
 }
 
-// StoreLogs adds multiple log entries to the bulk indexer.
+// RejectedError lists the log entries Elasticsearch refused to index, by their position in the
+// stored slice. Unlike transport errors and 5xx or 429 responses, the failure is about the entries themselves.
+type RejectedError struct {
+	Items map[int]error
+}
+
+func (e *RejectedError) Error() string {
+	positions := make([]int, 0, len(e.Items))
+	for i := range e.Items {
+		positions = append(positions, i)
+	}
+	slices.Sort(positions)
+	return fmt.Sprintf("elasticsearch rejected %d log entries, first at position %d: %v", len(positions), positions[0], e.Items[positions[0]])
+}
+
+// bulkCall collects what happened to the items of one StoreLogs call, reported by the indexer workers.
+type bulkCall struct {
+	mu        sync.Mutex
+	indexed   int
+	rejected  map[int]error
+	transient error // First error of a whole bulk request or of an item worth retrying as is
+}
+
+func (c *bulkCall) onError(ctx context.Context, err error) {
+	log.Error().Err(err).Msg("BulkIndexer error")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.transient == nil {
+		c.transient = err
+	}
+}
+
+func (c *bulkCall) onSuccess() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.indexed++
+}
+
+func (c *bulkCall) onFailure(position int, res esutil.BulkIndexerResponseItem, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case err != nil || res.Status == http.StatusTooManyRequests || res.Status >= http.StatusInternalServerError:
+		if c.transient == nil {
+			if err == nil {
+				err = fmt.Errorf("status %d, %s: %s", res.Status, res.Error.Type, res.Error.Reason)
+			}
+			c.transient = err
+		}
+	default:
+		c.rejected[position] = fmt.Errorf("status %d, %s: %s", res.Status, res.Error.Type, res.Error.Reason)
+	}
+}
+
+// err is nil once every one of total items is indexed, a *RejectedError when the only failures are
+// items Elasticsearch refused, and the transport error otherwise.
+func (c *bulkCall) err(total int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.transient != nil:
+		return fmt.Errorf("bulk indexing failed: %w", c.transient)
+	case c.indexed+len(c.rejected) < total:
+		return fmt.Errorf("bulk indexing failed: %d of %d log entries were not acknowledged", total-c.indexed-len(c.rejected), total)
+	case len(c.rejected) > 0:
+		return &RejectedError{Items: c.rejected}
+	}
+	return nil
+}
+
+// StoreLogs indexes the log entries and waits for Elasticsearch to acknowledge them. Each call flushes
+// its own BulkIndexer, so failures are reported to the caller rather than only to the callbacks.
 func (s *elasticLogStore) StoreLogs(ctx context.Context, logs []model.LogEntry) error {
 	if len(logs) == 0 {
 		return nil
 	}
 
-	currentFailed := atomic.LoadUint64(&s.countFailed)
+	call := &bulkCall{rejected: make(map[int]error)}
+	cfg := s.indexerConfig
+	cfg.OnError = call.onError
+	bi, err := esutil.NewBulkIndexer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed creating BulkIndexer: %w", err)
+	}
 
-	for _, logEntry := range logs {
+	for i, logEntry := range logs {
 		data, err := json.Marshal(logEntry)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to marshal log entry for Elasticsearch")
 			atomic.AddUint64(&s.countFailed, 1)
+			call.mu.Lock()
+			call.rejected[i] = err
+			call.mu.Unlock()
 			continue
 		}
 
-		err = s.bulkIndexer.Add(
+		err = bi.Add(
 			ctx,
 			esutil.BulkIndexerItem{
-				Action: "index",
-				Index:  s.getIndexName(),
-				Body:   bytes.NewReader(data),
+				Action:     "index",
+				Index:      s.getIndexName(),
+				DocumentID: logEntry.ID, // Redelivered entries replace their earlier copy
+				Body:       bytes.NewReader(data),
+				OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+					s.onSuccess(ctx, item, res)
+					call.onSuccess()
+				},
+				OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+					s.onFailure(ctx, item, res, err)
+					call.onFailure(i, res, err)
+				},
 			},
 		)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to add item to BulkIndexer")
 			atomic.AddUint64(&s.countFailed, 1)
+			call.onError(ctx, err)
+			break
 		}
 	}
-	log.Debug().Int("count", len(logs)).Msg("Added log entries to Elasticsearch BulkIndexer queue")
 
-	if atomic.LoadUint64(&s.countFailed) > currentFailed {
-		return errors.New("one or more logs failed during bulk indexing attempt")
+	// Closing flushes what is left and waits for the responses
+	errClose := bi.Close(ctx)
+	s.addStats(bi.Stats())
+	if errClose != nil {
+		return fmt.Errorf("failed flushing BulkIndexer: %w", errClose)
 	}
+	log.Debug().Int("count", len(logs)).Msg("Flushed log entries to Elasticsearch")
+	return call.err(len(logs))
+}
 
-	return nil
+// addStats adds the stats of a closed BulkIndexer to the store's totals.
+func (s *elasticLogStore) addStats(stats esutil.BulkIndexerStats) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats.NumAdded += stats.NumAdded
+	s.stats.NumFlushed += stats.NumFlushed
+	s.stats.NumFailed += stats.NumFailed
+	s.stats.NumIndexed += stats.NumIndexed
+	s.stats.NumCreated += stats.NumCreated
+	s.stats.NumUpdated += stats.NumUpdated
+	s.stats.NumDeleted += stats.NumDeleted
+	s.stats.NumRequests += stats.NumRequests
+	s.stats.FlushedBytes += stats.FlushedBytes
 }
-func (s *elasticLogStore) Close(ctx context.Context) error {
-	log.Info().Msg("Attempting to close BulkIndexer...")
-	err := s.bulkIndexer.Close(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("Error closing BulkIndexer")
-	} else {
-		log.Info().Msg("BulkIndexer closed.")
+
+// bulkStats is the sum of the stats of every BulkIndexer closed so far.
+func (s *elasticLogStore) bulkStats() esutil.BulkIndexerStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+func (s *elasticLogStore) onSuccess(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+	atomic.AddUint64(&s.countSuccessful, 1)
+}
+
+func (s *elasticLogStore) onFailure(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+	atomic.AddUint64(&s.countFailed, 1)
+	if err == nil {
+		err = fmt.Errorf("%s: %s", res.Error.Type, res.Error.Reason)
 	}
+	log.Error().Err(err).Str("document_id", item.DocumentID).Int("status", res.Status).Msg("Elasticsearch rejected log entry")
+}
 
-	stats := s.bulkIndexer.Stats()
+func (s *elasticLogStore) Close(ctx context.Context) error {
+	// Every StoreLogs call flushes and closes its own BulkIndexer, there is nothing left to flush
+	stats := s.bulkStats()
 	log.Info().
 		Uint64("indexed", stats.NumIndexed).
 		Uint64("added", stats.NumAdded).
@@ -188,7 +309,47 @@ func (s *elasticLogStore) Close(ctx context.Context) error {
 		Uint64("callback_failed", atomic.LoadUint64(&s.countFailed)).
 		Msg("Elasticsearch BulkIndexer final callback stats")
 
-	return err
+	return nil
+}
+
+// putIndexTemplate maps the extracted fields with their proper types in every daily index.
+// Templates only apply to indices created afterwards, so the current day's index keeps its mapping.
+func (s *elasticLogStore) putIndexTemplate(ctx context.Context, fieldTypes map[string]string) error {
+	properties := make(map[string]interface{}, len(fieldTypes))
+	for name, typ := range fieldTypes {
+		properties[name] = map[string]string{"type": typ}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{fmt.Sprintf("%s-*", s.indexPrefix)},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"fields": map[string]interface{}{"properties": properties},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	templateName := s.indexPrefix + "-fields"
+	// A composable template: the legacy _template API is deprecated since Elasticsearch 7.8, and the
+	// client has no call for the new one, so the v8 request goes through its transport
+	req := esapi.IndicesPutIndexTemplateRequest{
+		Name: templateName,
+		Body: bytes.NewReader(body),
+	}
+	res, err := req.Do(ctx, s.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("put index template returned error status: %s", res.Status())
+	}
+	log.Info().Str("template", templateName).Int("field_count", len(fieldTypes)).Msg("Elasticsearch index template for extracted fields is up to date")
+	return nil
 }
 
 // getIndexName generates the index name, e.g., "applogs-YYYY-MM-DD"
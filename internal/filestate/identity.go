@@ -0,0 +1,110 @@
+package filestate
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// headSize is how many leading bytes of a file are checksummed to recognize it after a rename,
+// and to notice that a path was truncated and rewritten while keeping its inode (copytruncate).
+const headSize = 1024
+
+// FileState records the read offset of a file together with what identifies it, so the offset
+// is only applied to the file it was taken from.
+type FileState struct {
+	Offset   int64  `json:"offset"`
+	Device   uint64 `json:"device,omitempty"`
+	Inode    uint64 `json:"inode,omitempty"`
+	HeadHash string `json:"head_hash,omitempty"` // SHA-1 of the first HeadSize bytes
+	HeadSize int64  `json:"head_size,omitempty"`
+	Complete bool   `json:"complete,omitempty"` // Compressed files and archives are read once, then skipped
+
+	// Timestamp of the last entry before Offset, inherited by lines without one when reading resumes
+	LastTimestamp *time.Time `json:"last_timestamp,omitempty"`
+}
+
+// UnmarshalJSON also accepts the bare offsets written by earlier versions, which carry no identity.
+func (s *FileState) UnmarshalJSON(data []byte) error {
+	var offset int64
+	if err := json.Unmarshal(data, &offset); err == nil {
+		*s = FileState{Offset: offset}
+		return nil
+	}
+	type plain FileState
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// Identify returns the identity of an open file, with Offset left at zero.
+func Identify(file *os.File, info os.FileInfo) (FileState, error) {
+	hash, n, err := headHash(file, min(info.Size(), headSize))
+	if err != nil {
+		return FileState{}, err
+	}
+	device, inode := fileID(info)
+	return FileState{Device: device, Inode: inode, HeadHash: hash, HeadSize: n}, nil
+}
+
+// Matches reports whether file is the file s was recorded from: same device and inode when both
+// are known, and still starting with the bytes that were checksummed.
+func (s FileState) Matches(file *os.File, info os.FileInfo) (bool, error) {
+	device, inode := fileID(info)
+	if s.Inode != 0 && inode != 0 && (s.Inode != inode || s.Device != device) {
+		return false, nil
+	}
+	return s.HasHead(file, info)
+}
+
+// HasHead reports whether file starts with the bytes s was checksummed over, regardless of its inode.
+// This recognizes copies made by copytruncate rotation.
+func (s FileState) HasHead(file *os.File, info os.FileInfo) (bool, error) {
+	if s.HeadHash == "" {
+		// Legacy state without identity: all we can check is that the file did not shrink
+		return info.Size() >= s.Offset, nil
+	}
+	if info.Size() < s.HeadSize {
+		return false, nil
+	}
+	hash, n, err := headHash(file, s.HeadSize)
+	if err != nil {
+		return false, err
+	}
+	return n == s.HeadSize && hash == s.HeadHash, nil
+}
+
+// FindByIdentity returns the tracked path, other than path, whose recorded device and inode are
+// those of file and whose head checksum still matches, i.e. where file was tracked before a rename.
+func (state FileProcessState) FindByIdentity(path string, file *os.File, info os.FileInfo) (string, FileState, bool) {
+	device, inode := fileID(info)
+	if inode == 0 {
+		return "", FileState{}, false
+	}
+	for trackedPath, saved := range state {
+		if trackedPath == path || saved.Inode != inode || saved.Device != device {
+			continue
+		}
+		// The old path may now hold a new file that reuses nothing but the name
+		if current, err := os.Stat(trackedPath); err == nil && os.SameFile(current, info) {
+			continue
+		}
+		if ok, err := saved.HasHead(file, info); err == nil && ok {
+			return trackedPath, saved, true
+		}
+	}
+	return "", FileState{}, false
+}
+
+// headHash checksums the first size bytes of file, or fewer if it is shorter, and returns how many were read.
+func headHash(file *os.File, size int64) (string, int64, error) {
+	buf := make([]byte, size)
+	n, err := file.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+	sum := sha1.Sum(buf[:n])
+	return hex.EncodeToString(sum[:]), int64(n), nil
+}
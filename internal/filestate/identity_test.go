@@ -0,0 +1,172 @@
+package filestate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/filestate"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func appendTo(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+// identify records the identity of the file at path, read up to its end.
+func identify(t *testing.T, path string) filestate.FileState {
+	t.Helper()
+	file, info := open(t, path)
+	state, err := filestate.Identify(file, info)
+	require.NoError(t, err)
+	state.Offset = info.Size()
+	return state
+}
+
+func open(t *testing.T, path string) (*os.File, os.FileInfo) {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	info, err := file.Stat()
+	require.NoError(t, err)
+	return file, info
+}
+
+func TestFileState_MatchesAfterRotation(t *testing.T) {
+	tests := []struct {
+		name string
+		// rotate changes the file at path, recorded as saved, the way a writer or logrotate would
+		rotate         func(t *testing.T, path string)
+		matches        bool // Whether path is still the recorded file
+		rotatedHasHead bool // Whether path.1 holds the recorded file
+	}{
+		{
+			name:    "appended to",
+			rotate:  func(t *testing.T, path string) { appendTo(t, path, "third line\n") },
+			matches: true,
+		},
+		{
+			name: "renamed and recreated",
+			rotate: func(t *testing.T, path string) {
+				require.NoError(t, os.Rename(path, path+".1"))
+				writeFile(t, path, "a new file\n")
+			},
+			rotatedHasHead: true,
+		},
+		{
+			name: "copytruncate",
+			rotate: func(t *testing.T, path string) {
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				writeFile(t, path+".1", string(content))
+				require.NoError(t, os.Truncate(path, 0))
+				appendTo(t, path, "written after the copy\n")
+			},
+			rotatedHasHead: true,
+		},
+		{
+			name: "truncated and regrown past the offset",
+			rotate: func(t *testing.T, path string) {
+				require.NoError(t, os.Truncate(path, 0))
+				appendTo(t, path, "a different first line, long enough to pass the saved offset\n")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			writeFile(t, path, "first line\nsecond line\n")
+			saved := identify(t, path)
+
+			tt.rotate(t, path)
+
+			file, info := open(t, path)
+			matches, err := saved.Matches(file, info)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, matches)
+
+			if _, err := os.Stat(path + ".1"); err == nil {
+				rotated, rotatedInfo := open(t, path+".1")
+				hasHead, err := saved.HasHead(rotated, rotatedInfo)
+				require.NoError(t, err)
+				assert.Equal(t, tt.rotatedHasHead, hasHead)
+			}
+		})
+	}
+}
+
+func TestFileProcessState_FindByIdentity(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "app.log")
+	writeFile(t, oldPath, "first line\n")
+	state := filestate.FileProcessState{oldPath: identify(t, oldPath)}
+
+	t.Run("renamed file", func(t *testing.T) {
+		newPath := filepath.Join(dir, "app-renamed.log")
+		require.NoError(t, os.Rename(oldPath, newPath))
+		file, info := open(t, newPath)
+
+		trackedPath, saved, ok := state.FindByIdentity(newPath, file, info)
+		require.True(t, ok)
+		assert.Equal(t, oldPath, trackedPath)
+		assert.Equal(t, int64(len("first line\n")), saved.Offset)
+	})
+
+	t.Run("renamed file with a new file at the old path", func(t *testing.T) {
+		writeFile(t, oldPath, "first line\n")
+		newPath := filepath.Join(dir, "app-renamed.log")
+		file, info := open(t, newPath)
+
+		trackedPath, _, ok := state.FindByIdentity(newPath, file, info)
+		require.True(t, ok)
+		assert.Equal(t, oldPath, trackedPath)
+	})
+
+	t.Run("unrelated file", func(t *testing.T) {
+		otherPath := filepath.Join(dir, "other.log")
+		writeFile(t, otherPath, "something else\n")
+		file, info := open(t, otherPath)
+
+		_, _, ok := state.FindByIdentity(otherPath, file, info)
+		assert.False(t, ok)
+	})
+}
+
+func TestManager_LoadsLegacyOffsets(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.json")
+	writeFile(t, statePath, `{"/logs/app.log": 42, "/logs/new.log": {"offset": 7, "inode": 12, "head_hash": "abc", "head_size": 7}}`)
+
+	state, err := filestate.NewManager(statePath).LoadState()
+	require.NoError(t, err)
+	assert.Equal(t, filestate.FileState{Offset: 42}, state["/logs/app.log"])
+	assert.Equal(t, filestate.FileState{Offset: 7, Inode: 12, HeadHash: "abc", HeadSize: 7}, state["/logs/new.log"])
+
+	// Without identity, a file is only recognized by not having shrunk
+	path := filepath.Join(dir, "app.log")
+	writeFile(t, path, "this file is longer than 42 bytes, so it is still the same one\n")
+	file, info := open(t, path)
+	matches, err := state["/logs/app.log"].Matches(file, info)
+	require.NoError(t, err)
+	assert.True(t, matches)
+
+	require.NoError(t, os.Truncate(path, 10))
+	file, info = open(t, path)
+	matches, err = state["/logs/app.log"].Matches(file, info)
+	require.NoError(t, err)
+	assert.False(t, matches)
+}
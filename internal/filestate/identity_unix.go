@@ -0,0 +1,15 @@
+//go:build unix
+
+package filestate
+
+import (
+	"os"
+	"syscall"
+)
+
+func fileID(info os.FileInfo) (device, inode uint64) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev), uint64(stat.Ino)
+	}
+	return 0, 0
+}
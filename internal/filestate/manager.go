@@ -8,7 +8,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-type FileProcessState map[string]int64
+// FileProcessState maps each log file path to how far it has been read.
+type FileProcessState map[string]FileState
+
 type Manager interface {
 	LoadState() (FileProcessState, error)
 	SaveState(state FileProcessState) error
@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"skeleton-internship-backend/internal/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Versions of the log topic message format. Version 1 is a plain JSON model.LogEntry without a
+// version field. Later versions are envelopes starting with {"v":<version>, so consumers read the
+// messages of old and new producers alike during a rollout.
+const (
+	MessageVersionJSON    = 1
+	MessageVersionCompact = 2
+)
+
+// Names of the versions in KAFKA_MESSAGE_ENCODING.
+var messageVersions = map[string]int{
+	"json":    MessageVersionJSON,
+	"compact": MessageVersionCompact,
+}
+
+// versionedPrefix starts every versioned message: encoding/json writes struct fields in order.
+var versionedPrefix = []byte(`{"v":`)
+
+// compactLogEntry is version 2: short keys, and no second copy of the content when raw_log holds it.
+type compactLogEntry struct {
+	Version           int               `json:"v"`
+	ID                string            `json:"id,omitempty"`
+	Timestamp         time.Time         `json:"ts"`
+	TimestampInferred bool              `json:"tsi,omitempty"`
+	Level             string            `json:"lvl,omitempty"`
+	Component         string            `json:"cmp,omitempty"`
+	Content           string            `json:"msg,omitempty"`   // Only when ContentSpan is nil
+	ContentSpan       *[2]int           `json:"msgAt,omitempty"` // Byte range of the content in Raw
+	Application       string            `json:"app,omitempty"`
+	ContainerID       string            `json:"ctr,omitempty"`
+	Attempt           int               `json:"att,omitempty"`
+	Hostname          string            `json:"host,omitempty"`
+	SourceFile        string            `json:"src,omitempty"`
+	Raw               string            `json:"raw"`
+	EventID           string            `json:"evt,omitempty"`
+	EventParams       []string          `json:"evtParams,omitempty"`
+	Fields            map[string]any    `json:"fields,omitempty"`
+	StackTrace        *model.StackTrace `json:"stack,omitempty"`
+}
+
+// ParseMessageEncoding returns the message version named by KAFKA_MESSAGE_ENCODING.
+func ParseMessageEncoding(name string) (int, error) {
+	version, ok := messageVersions[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("invalid kafka message encoding %q: expected json or compact", name)
+	}
+	return version, nil
+}
+
+// EncodeLogEntry encodes entry as a log topic message of the given version.
+func EncodeLogEntry(entry model.LogEntry, version int) ([]byte, error) {
+	switch version {
+	case MessageVersionJSON:
+		return json.Marshal(entry)
+	case MessageVersionCompact:
+		compact := compactLogEntry{
+			Version:           MessageVersionCompact,
+			ID:                entry.ID,
+			Timestamp:         entry.Timestamp,
+			TimestampInferred: entry.TimestampInferred,
+			Level:             entry.Level,
+			Component:         entry.Component,
+			Application:       entry.Application,
+			ContainerID:       entry.ContainerID,
+			Attempt:           entry.Attempt,
+			Hostname:          entry.Hostname,
+			SourceFile:        entry.SourceFile,
+			Raw:               entry.Raw,
+			EventID:           entry.EventID,
+			EventParams:       entry.EventParams,
+			Fields:            entry.Fields,
+			StackTrace:        entry.StackTrace,
+		}
+		if start, ok := contentStart(entry.Raw, entry.Content); ok {
+			compact.ContentSpan = &[2]int{start, start + len(entry.Content)}
+		} else {
+			compact.Content = entry.Content
+		}
+		return json.Marshal(compact)
+	default:
+		return nil, fmt.Errorf("unsupported log message version %d", version)
+	}
+}
+
+// contentStart locates content in raw. Content is the header's message followed by the
+// continuation lines, and raw the header line followed by the same lines, so it usually ends raw.
+func contentStart(raw, content string) (int, bool) {
+	// JSON replaces invalid UTF-8 with U+FFFD, which would shift the span
+	if content == "" || !utf8.ValidString(raw) {
+		return 0, false
+	}
+	if strings.HasSuffix(raw, content) {
+		return len(raw) - len(content), true
+	}
+	start := strings.Index(raw, content)
+	return start, start >= 0
+}
+
+// DecodeLogEntry decodes a log topic message of any known version.
+func DecodeLogEntry(value []byte) (*model.LogEntry, error) {
+	if !bytes.HasPrefix(value, versionedPrefix) {
+		var entry model.LogEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return nil, err
+		}
+		return &entry, nil
+	}
+
+	var compact compactLogEntry
+	if err := json.Unmarshal(value, &compact); err != nil {
+		return nil, err
+	}
+	if compact.Version != MessageVersionCompact {
+		return nil, fmt.Errorf("unsupported log message version %d", compact.Version)
+	}
+	entry := &model.LogEntry{
+		ID:                compact.ID,
+		Timestamp:         compact.Timestamp,
+		TimestampInferred: compact.TimestampInferred,
+		Level:             compact.Level,
+		Component:         compact.Component,
+		Content:           compact.Content,
+		Application:       compact.Application,
+		ContainerID:       compact.ContainerID,
+		Attempt:           compact.Attempt,
+		Hostname:          compact.Hostname,
+		SourceFile:        compact.SourceFile,
+		Raw:               compact.Raw,
+		EventID:           compact.EventID,
+		EventParams:       compact.EventParams,
+		Fields:            compact.Fields,
+		StackTrace:        compact.StackTrace,
+	}
+	if span := compact.ContentSpan; span != nil {
+		if span[0] < 0 || span[0] > span[1] || span[1] > len(compact.Raw) {
+			return nil, fmt.Errorf("invalid log message: content span %v outside raw_log", *span)
+		}
+		entry.Content = compact.Raw[span[0]:span[1]]
+	}
+	return entry, nil
+}
@@ -0,0 +1,68 @@
+package kafka_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/kafka"
+	"skeleton-internship-backend/internal/model"
+)
+
+func TestLogEntryEncoding_RoundTrip(t *testing.T) {
+	entries := []model.LogEntry{
+		{
+			ID:          "abc",
+			Timestamp:   time.Date(2017, 1, 24, 14, 9, 12, 0, time.UTC),
+			Level:       "ERROR",
+			Component:   "executor.Executor",
+			Content:     "Exception in task 0.0\njava.lang.IllegalStateException: boom\n\tat Foo.bar(Foo.java:10)",
+			Raw:         "17/01/24 14:09:12 ERROR executor.Executor: Exception in task 0.0\njava.lang.IllegalStateException: boom\n\tat Foo.bar(Foo.java:10)",
+			Application: "application_1485248649253_0186",
+			EventParams: []string{"0.0"},
+			Fields:      map[string]any{"task_id": "0.0"},
+		},
+		// Trailing spaces are trimmed from the message, so the content is not the end of the raw line
+		{Content: "Started\nmore", Raw: "INFO x: Started  \nmore"},
+		// JSON lines: the content is a decoded string field
+		{Content: `say "hi"`, Raw: `{"message":"say \"hi\""}`},
+		{Content: "caf\xe9", Raw: "INFO x: caf\xe9"},
+		{},
+	}
+	for _, entry := range entries {
+		for _, version := range []int{kafka.MessageVersionJSON, kafka.MessageVersionCompact} {
+			value, err := kafka.EncodeLogEntry(entry, version)
+			require.NoError(t, err)
+			decoded, err := kafka.DecodeLogEntry(value)
+			require.NoError(t, err)
+
+			// Compare through JSON, which is what Elasticsearch gets
+			want, _ := json.Marshal(entry)
+			got, _ := json.Marshal(decoded)
+			assert.JSONEq(t, string(want), string(got), "version %d", version)
+		}
+	}
+}
+
+func TestLogEntryEncoding_Compact(t *testing.T) {
+	entry := model.LogEntry{Content: "Registered executor", Raw: "17/01/24 14:09:12 INFO Worker: Registered executor"}
+	value, err := kafka.EncodeLogEntry(entry, kafka.MessageVersionCompact)
+	require.NoError(t, err)
+	assert.Contains(t, string(value), `{"v":2,`)
+	assert.Equal(t, 1, strings.Count(string(value), "Registered executor"))
+
+	_, err = kafka.DecodeLogEntry([]byte(`{"v":3,"raw":""}`))
+	assert.ErrorContains(t, err, "unsupported log message version 3")
+	_, err = kafka.DecodeLogEntry([]byte(`{"v":2,"raw":"abc","msgAt":[1,9]}`))
+	assert.Error(t, err)
+
+	version, err := kafka.ParseMessageEncoding("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, kafka.MessageVersionJSON, version)
+	_, err = kafka.ParseMessageEncoding("protobuf")
+	assert.Error(t, err)
+}
@@ -2,7 +2,7 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"skeleton-internship-backend/config"
 	"skeleton-internship-backend/internal/model"
 	"time"
@@ -13,29 +13,57 @@ import (
 )
 
 type LogConsumer interface {
+	// Consume runs handle in its own goroutine for each log topic partition assigned to this member
+	// of the consumer group, until ctx is cancelled. On a rebalance the handlers' context ends, and
+	// they are started again for the new assignment once they all returned.
+	Consume(ctx context.Context, handle func(ctx context.Context, partition PartitionConsumer)) error
+	Close() error
+}
+
+// PartitionConsumer reads one partition of the log topic in order.
+type PartitionConsumer interface {
+	Partition() int
+	// FetchMessage returns the original message along with the error when it is not a log entry.
 	FetchMessage(ctx context.Context) (*model.LogEntry, kafka.Message, error)
+	// CommitMessages commits the offset following the last of msgs.
 	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
-	Close() error
 }
 
 type kafkaLogConsumer struct {
-	reader *kafka.Reader
+	group   *kafka.ConsumerGroup
+	dialer  *kafka.Dialer
+	brokers []string
+	topic   string
+	groupID string
+}
+
+// NewLogConsumer returns the embedded queue when KAFKA_BACKEND selects it, else a Kafka consumer group member.
+func NewLogConsumer(lc fx.Lifecycle, cfg *config.Config, security *Security, queue *EmbeddedQueue) (LogConsumer, error) {
+	if queue != nil {
+		return queue, nil
+	}
+	return NewKafkaLogConsumer(lc, cfg, security)
 }
 
-func NewKafkaLogConsumer(lc fx.Lifecycle, cfg *config.Config) (LogConsumer, error) {
-
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        cfg.Kafka.Brokers,
-		GroupID:        cfg.Kafka.ConsumerGroup,
-		Topic:          cfg.Kafka.LogTopic,
-		MinBytes:       10e3,             // 10KB
-		MaxBytes:       10e6,             // 10MB
-		MaxWait:        10 * time.Second, // Wait up to 10 second for data
-		CommitInterval: 0,
-		StartOffset:    kafka.FirstOffset,
+func NewKafkaLogConsumer(lc fx.Lifecycle, cfg *config.Config, security *Security) (LogConsumer, error) {
+	dialer := security.Dialer()
+	group, err := kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:                    cfg.Kafka.ConsumerGroup,
+		Brokers:               cfg.Kafka.Brokers,
+		Dialer:                dialer,
+		Topics:                []string{cfg.Kafka.LogTopic},
+		StartOffset:           kafka.FirstOffset,
+		WatchPartitionChanges: true, // Rebalance when partitions are added
 	})
+	if err != nil {
+		return nil, err
+	}
 	c := &kafkaLogConsumer{
-		reader: reader,
+		group:   group,
+		dialer:  dialer,
+		brokers: cfg.Kafka.Brokers,
+		topic:   cfg.Kafka.LogTopic,
+		groupID: cfg.Kafka.ConsumerGroup,
 	}
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
@@ -51,10 +79,76 @@ func NewKafkaLogConsumer(lc fx.Lifecycle, cfg *config.Config) (LogConsumer, erro
 	return c, nil
 }
 
-func (c *kafkaLogConsumer) FetchMessage(ctx context.Context) (*model.LogEntry, kafka.Message, error) {
+func (c *kafkaLogConsumer) Consume(ctx context.Context, handle func(ctx context.Context, partition PartitionConsumer)) error {
+	for {
+		gen, err := c.group.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, kafka.ErrGroupClosed) {
+				return err
+			}
+			// The group keeps trying to join on its own
+			log.Warn().Err(err).Str("group", c.groupID).Msg("Failed to join Kafka consumer group, retrying")
+			continue
+		}
+
+		assignments := gen.Assignments[c.topic]
+		partitions := make([]int, len(assignments))
+		for i, assignment := range assignments {
+			partitions[i] = assignment.ID
+		}
+		log.Info().Str("group", c.groupID).Int32("generation", gen.ID).Ints("partitions", partitions).Msg("Kafka partitions assigned")
+
+		for _, assignment := range assignments {
+			assignment := assignment
+			// The generation ends as soon as one of its goroutines returns, so each returns only once it ended or ctx is cancelled
+			gen.Start(func(genCtx context.Context) {
+				partitionCtx, cancel := context.WithCancel(genCtx)
+				defer cancel()
+				stop := context.AfterFunc(ctx, cancel)
+				defer stop()
+
+				reader := kafka.NewReader(kafka.ReaderConfig{
+					Brokers:   c.brokers,
+					Dialer:    c.dialer,
+					Topic:     c.topic,
+					Partition: assignment.ID,
+					MinBytes:  10e3,             // 10KB
+					MaxBytes:  10e6,             // 10MB
+					MaxWait:   10 * time.Second, // Wait up to 10 second for data
+				})
+				defer reader.Close()
+				if err := reader.SetOffset(assignment.Offset); err != nil {
+					log.Error().Err(err).Int("partition", assignment.ID).Msg("Failed to seek Kafka partition")
+					return
+				}
+				handle(partitionCtx, &kafkaPartitionConsumer{gen: gen, reader: reader, topic: c.topic, partition: assignment.ID})
+			})
+		}
+	}
+}
+
+func (c *kafkaLogConsumer) Close() error {
+	return c.group.Close()
+}
+
+type kafkaPartitionConsumer struct {
+	gen       *kafka.Generation
+	reader    *kafka.Reader
+	topic     string
+	partition int
+}
+
+func (c *kafkaPartitionConsumer) Partition() int {
+	return c.partition
+}
+
+func (c *kafkaPartitionConsumer) FetchMessage(ctx context.Context) (*model.LogEntry, kafka.Message, error) {
 	msg, err := c.reader.FetchMessage(ctx)
 	if err != nil {
-		log.Debug().Msg("Fail when fetching Kafka message.")
+		log.Debug().Int("partition", c.partition).Msg("Fail when fetching Kafka message.")
 		return nil, kafka.Message{}, err
 	}
 	log.Debug().
@@ -62,26 +156,25 @@ func (c *kafkaLogConsumer) FetchMessage(ctx context.Context) (*model.LogEntry, k
 		Int("partition", msg.Partition).
 		Int64("offset", msg.Offset).
 		Msg("Fetched message from Kafka")
-	var logEntry model.LogEntry
-	if err := json.Unmarshal(msg.Value, &logEntry); err != nil {
-		log.Error().Err(err).Int64("offset", msg.Offset).Msg("Failed to unmarshal Kafka message value")
+	// Messages of every version are accepted, so producers can be upgraded after consumers
+	logEntry, err := DecodeLogEntry(msg.Value)
+	if err != nil {
+		log.Error().Err(err).Int("partition", msg.Partition).Int64("offset", msg.Offset).Msg("Failed to unmarshal Kafka message value")
 		return nil, msg, err
 	}
-	return &logEntry, msg, nil
+	return logEntry, msg, nil
 }
 
-func (c *kafkaLogConsumer) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+func (c *kafkaPartitionConsumer) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
 	if len(msgs) == 0 {
 		return nil
 	}
-	err := c.reader.CommitMessages(ctx, msgs...)
+	next := msgs[len(msgs)-1].Offset + 1
+	err := c.gen.CommitOffsets(map[string]map[int]int64{c.topic: {c.partition: next}})
 	if err != nil {
-		log.Error().Err(err).Int("count", len(msgs)).Msg("Failed to commit Kafka messages")
+		log.Error().Err(err).Int("partition", c.partition).Int("count", len(msgs)).Msg("Failed to commit Kafka messages")
 		return err
 	}
-	log.Debug().Int("count", len(msgs)).Int64("last_offset", msgs[len(msgs)-1].Offset).Msg("Committed Kafka messages")
+	log.Debug().Int("partition", c.partition).Int("count", len(msgs)).Int64("last_offset", next-1).Msg("Committed Kafka messages")
 	return nil
 }
-func (c *kafkaLogConsumer) Close() error {
-	return c.reader.Close()
-}
@@ -0,0 +1,362 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/model"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/fx"
+)
+
+// Headers a dead letter carries next to the original key and value.
+const (
+	headerStage             = "dlq.stage"
+	headerError             = "dlq.error"
+	headerAttempts          = "dlq.attempts"
+	headerFailedAt          = "dlq.failed_at"
+	headerOriginalTopic     = "dlq.original_topic"
+	headerOriginalPartition = "dlq.original_partition"
+	headerOriginalOffset    = "dlq.original_offset"
+	headerReplayOf          = "dlq.replay_of" // Set on messages replayed into the log topic
+)
+
+var (
+	// ErrDeadLetterDisabled is returned when KAFKA_DEAD_LETTER_TOPIC is empty or the embedded queue is used.
+	ErrDeadLetterDisabled = errors.New("dead-letter topic is not configured")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+// DeadLetterQuery selects dead letters, newest first.
+type DeadLetterQuery struct {
+	Partition    int   // -1 for every partition
+	BeforeOffset int64 // With Partition, only letters below this offset; 0 for the newest
+	Limit        int
+}
+
+// DeadLetterQueue keeps the messages the consumer gave up on in KAFKA_DEAD_LETTER_TOPIC, and gives
+// them back for inspection or replay into the log topic. Kafka cannot delete single messages, so
+// replayed letters stay in the topic until its retention removes them.
+type DeadLetterQueue interface {
+	// Enabled reports whether a dead-letter topic is configured. If not, the other methods return ErrDeadLetterDisabled.
+	Enabled() bool
+	// Send returns once every letter is acknowledged by all in-sync replicas. ID, Partition and Offset are ignored.
+	Send(ctx context.Context, letters []model.DeadLetter) error
+	// List returns up to query.Limit letters and how many the selected partitions hold in all.
+	List(ctx context.Context, query DeadLetterQuery) ([]model.DeadLetter, int64, error)
+	Get(ctx context.Context, partition int, offset int64) (*model.DeadLetter, error)
+	// Replay writes the original message of each letter back to the log topic.
+	Replay(ctx context.Context, letters []model.DeadLetter) error
+	Close() error
+}
+
+type kafkaDeadLetterQueue struct {
+	client   *kafka.Client
+	writer   *kafka.Writer
+	topic    string
+	logTopic string
+}
+
+func NewKafkaDeadLetterQueue(lc fx.Lifecycle, cfg *config.Config, security *Security) (DeadLetterQueue, error) {
+	q := &kafkaDeadLetterQueue{
+		topic:    cfg.Kafka.DeadLetterTopic,
+		logTopic: cfg.Kafka.LogTopic,
+	}
+	if cfg.Kafka.Backend == BackendEmbedded {
+		log.Warn().Msg("Dead-letter topic is not available with the embedded queue, failed batches will be retried until they succeed")
+		q.topic = ""
+		return q, nil
+	}
+	if q.topic == "" {
+		log.Warn().Msg("Kafka dead-letter topic is not configured, failed batches will be retried until they succeed")
+		return q, nil
+	}
+	if q.topic == q.logTopic {
+		return nil, fmt.Errorf("invalid kafka configuration: dead-letter topic %q is the log topic", q.topic)
+	}
+
+	addr := kafka.TCP(cfg.Kafka.Brokers...)
+	transport := security.Transport()
+	q.client = &kafka.Client{Addr: addr, Timeout: 10 * time.Second, Transport: transport}
+	q.writer = &kafka.Writer{
+		Addr:                   addr,
+		Transport:              transport,
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           10 * time.Millisecond,
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			log.Info().Msg("Closing Kafka dead-letter writer")
+			return q.Close()
+		},
+	})
+	log.Info().Strs("brokers", cfg.Kafka.Brokers).Str("topic", q.topic).Msg("Kafka dead-letter queue initialized")
+	return q, nil
+}
+
+func (q *kafkaDeadLetterQueue) Enabled() bool {
+	return q.topic != ""
+}
+
+func (q *kafkaDeadLetterQueue) Send(ctx context.Context, letters []model.DeadLetter) error {
+	if !q.Enabled() {
+		return ErrDeadLetterDisabled
+	}
+	if len(letters) == 0 {
+		return nil
+	}
+	messages := make([]kafka.Message, len(letters))
+	for i, letter := range letters {
+		failedAt := letter.FailedAt
+		if failedAt.IsZero() {
+			failedAt = time.Now()
+		}
+		messages[i] = kafka.Message{
+			Topic: q.topic,
+			Key:   []byte(letter.Key),
+			Value: []byte(letter.Value),
+			Headers: []kafka.Header{
+				{Key: headerStage, Value: []byte(letter.Stage)},
+				{Key: headerError, Value: []byte(letter.Error)},
+				{Key: headerAttempts, Value: []byte(strconv.Itoa(letter.Attempts))},
+				{Key: headerFailedAt, Value: []byte(failedAt.UTC().Format(time.RFC3339Nano))},
+				{Key: headerOriginalTopic, Value: []byte(letter.OriginalTopic)},
+				{Key: headerOriginalPartition, Value: []byte(strconv.Itoa(letter.OriginalPartition))},
+				{Key: headerOriginalOffset, Value: []byte(strconv.FormatInt(letter.OriginalOffset, 10))},
+			},
+		}
+	}
+	if err := q.writer.WriteMessages(ctx, messages...); err != nil {
+		log.Error().Err(err).Int("message_count", len(messages)).Str("topic", q.topic).Msg("Failed to write dead letters to Kafka")
+		return err
+	}
+	log.Warn().Int("message_count", len(messages)).Str("topic", q.topic).Str("stage", letters[0].Stage).Msg("Moved messages to the dead-letter topic")
+	return nil
+}
+
+func (q *kafkaDeadLetterQueue) List(ctx context.Context, query DeadLetterQuery) ([]model.DeadLetter, int64, error) {
+	if !q.Enabled() {
+		return nil, 0, ErrDeadLetterDisabled
+	}
+	partitions, err := q.partitionOffsets(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var letters []model.DeadLetter
+	var total int64
+	for _, partition := range partitions {
+		if query.Partition >= 0 && partition.Partition != query.Partition {
+			continue
+		}
+		total += partition.LastOffset - partition.FirstOffset
+		end := partition.LastOffset
+		if query.BeforeOffset > 0 && query.BeforeOffset < end {
+			end = query.BeforeOffset
+		}
+		read, err := q.read(ctx, partition.Partition, max(partition.FirstOffset, end-int64(query.Limit)), end)
+		if err != nil {
+			return nil, 0, err
+		}
+		letters = append(letters, read...)
+	}
+
+	sort.Slice(letters, func(i, j int) bool {
+		if !letters[i].FailedAt.Equal(letters[j].FailedAt) {
+			return letters[i].FailedAt.After(letters[j].FailedAt)
+		}
+		if letters[i].Partition != letters[j].Partition {
+			return letters[i].Partition < letters[j].Partition
+		}
+		return letters[i].Offset > letters[j].Offset
+	})
+	if len(letters) > query.Limit {
+		letters = letters[:query.Limit]
+	}
+	return letters, total, nil
+}
+
+func (q *kafkaDeadLetterQueue) Get(ctx context.Context, partition int, offset int64) (*model.DeadLetter, error) {
+	if !q.Enabled() {
+		return nil, ErrDeadLetterDisabled
+	}
+	letters, err := q.read(ctx, partition, offset, offset+1)
+	if errors.Is(err, kafka.OffsetOutOfRange) || errors.Is(err, kafka.UnknownTopicOrPartition) {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(letters) == 0 {
+		return nil, ErrDeadLetterNotFound
+	}
+	return &letters[0], nil
+}
+
+func (q *kafkaDeadLetterQueue) Replay(ctx context.Context, letters []model.DeadLetter) error {
+	if !q.Enabled() {
+		return ErrDeadLetterDisabled
+	}
+	if len(letters) == 0 {
+		return nil
+	}
+	messages := make([]kafka.Message, len(letters))
+	for i, letter := range letters {
+		messages[i] = kafka.Message{
+			Topic:   q.logTopic,
+			Key:     []byte(letter.Key),
+			Value:   []byte(letter.Value),
+			Headers: []kafka.Header{{Key: headerReplayOf, Value: []byte(letter.ID)}},
+		}
+	}
+	if err := q.writer.WriteMessages(ctx, messages...); err != nil {
+		log.Error().Err(err).Int("message_count", len(messages)).Str("topic", q.logTopic).Msg("Failed to replay dead letters")
+		return err
+	}
+	log.Info().Int("message_count", len(messages)).Str("topic", q.logTopic).Msg("Replayed dead letters into the log topic")
+	return nil
+}
+
+func (q *kafkaDeadLetterQueue) Close() error {
+	if q.writer == nil {
+		return nil
+	}
+	return q.writer.Close()
+}
+
+// partitionOffsets returns the first and next offset of each partition of the dead-letter topic,
+// or nothing if the topic was not created yet.
+func (q *kafkaDeadLetterQueue) partitionOffsets(ctx context.Context) ([]kafka.PartitionOffsets, error) {
+	metadata, err := q.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{q.topic}})
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata.Topics) == 0 || errors.Is(metadata.Topics[0].Error, kafka.UnknownTopicOrPartition) {
+		return nil, nil
+	}
+	if metadata.Topics[0].Error != nil {
+		return nil, metadata.Topics[0].Error
+	}
+
+	requests := make([]kafka.OffsetRequest, 0, 2*len(metadata.Topics[0].Partitions))
+	for _, partition := range metadata.Topics[0].Partitions {
+		requests = append(requests, kafka.FirstOffsetOf(partition.ID), kafka.LastOffsetOf(partition.ID))
+	}
+	offsets, err := q.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{q.topic: requests},
+	})
+	if err != nil {
+		return nil, err
+	}
+	partitions := offsets.Topics[q.topic]
+	for _, partition := range partitions {
+		if partition.Error != nil {
+			return nil, fmt.Errorf("failed to list offsets of partition %d: %w", partition.Partition, partition.Error)
+		}
+	}
+	return partitions, nil
+}
+
+// read returns the letters of a partition from offset from up to, but excluding, offset to.
+func (q *kafkaDeadLetterQueue) read(ctx context.Context, partition int, from, to int64) ([]model.DeadLetter, error) {
+	var letters []model.DeadLetter
+	for offset := from; offset < to; {
+		res, err := q.client.Fetch(ctx, &kafka.FetchRequest{
+			Topic:     q.topic,
+			Partition: partition,
+			Offset:    offset,
+			MinBytes:  1,
+			MaxBytes:  10e6,
+			MaxWait:   500 * time.Millisecond,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.Records == nil {
+			break
+		}
+
+		next := offset
+		for next < to {
+			record, err := res.Records.ReadRecord()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Fetches return whole record batches, which may start before offset
+			if record.Offset < offset {
+				continue
+			}
+			if record.Offset >= to {
+				break
+			}
+			letter, err := deadLetterOf(partition, record)
+			if err != nil {
+				return nil, err
+			}
+			letters = append(letters, letter)
+			next = record.Offset + 1
+		}
+		if next == offset {
+			// Nothing left below to, e.g. the remaining offsets were transaction markers
+			break
+		}
+		offset = next
+	}
+	return letters, nil
+}
+
+func deadLetterOf(partition int, record *kafka.Record) (model.DeadLetter, error) {
+	key, err := kafka.ReadAll(record.Key)
+	if err != nil {
+		return model.DeadLetter{}, err
+	}
+	value, err := kafka.ReadAll(record.Value)
+	if err != nil {
+		return model.DeadLetter{}, err
+	}
+	letter := model.DeadLetter{
+		ID:        fmt.Sprintf("%d-%d", partition, record.Offset),
+		Partition: partition,
+		Offset:    record.Offset,
+		Key:       string(key),
+		Value:     string(value),
+		FailedAt:  record.Time.UTC(),
+	}
+	for _, header := range record.Headers {
+		value := string(header.Value)
+		switch header.Key {
+		case headerStage:
+			letter.Stage = value
+		case headerError:
+			letter.Error = value
+		case headerAttempts:
+			letter.Attempts, _ = strconv.Atoi(value)
+		case headerFailedAt:
+			if failedAt, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				letter.FailedAt = failedAt
+			}
+		case headerOriginalTopic:
+			letter.OriginalTopic = value
+		case headerOriginalPartition:
+			letter.OriginalPartition, _ = strconv.Atoi(value)
+		case headerOriginalOffset:
+			letter.OriginalOffset, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return letter, nil
+}
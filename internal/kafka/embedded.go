@@ -0,0 +1,710 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/fx"
+)
+
+// Values of KAFKA_BACKEND.
+const (
+	BackendKafka    = "kafka"
+	BackendEmbedded = "embedded" // EmbeddedQueue, for deployments without brokers
+)
+
+const (
+	// recordHeaderSize is the CRC, the length of the rest, the timestamp in nanoseconds and the key length.
+	recordHeaderSize = 20
+	maxRecordSize    = 64 << 20
+	segmentSuffix    = ".wal"
+	commitFileName   = "commit"
+	partitionPrefix  = "partition-"
+)
+
+// ErrQueueClosed is returned by the EmbeddedQueue once it is closed.
+var ErrQueueClosed = errors.New("embedded queue is closed")
+
+// errTornRecord is a record cut short by a crash, or otherwise damaged.
+var errTornRecord = errors.New("torn or corrupt record")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// EmbeddedQueue is a LogProducer and LogConsumer for a single process, keeping messages in a
+// write-ahead log under KAFKA_QUEUE_DIRECTORY instead of a Kafka topic. Like the topic it has
+// partitions, entries are assigned to them by application, and each partition has a committed
+// offset: messages are fetched again after a restart until they are committed. Produce returns
+// once the messages are synced to disk. Segments are deleted once all their messages are committed.
+//
+// Only one process may use a directory at a time.
+type EmbeddedQueue struct {
+	dir        string
+	topic      string // Reported as the topic of fetched messages
+	version    int    // Message version entries are encoded in
+	balancer   kafka.Hash
+	partitions []*walPartition
+	closeOnce  sync.Once
+}
+
+// NewEmbeddedQueue opens the queue when KAFKA_BACKEND is "embedded", and returns nil for Kafka.
+func NewEmbeddedQueue(lc fx.Lifecycle, cfg *config.Config) (*EmbeddedQueue, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Kafka.Backend)) {
+	case "", BackendKafka:
+		return nil, nil
+	case BackendEmbedded:
+	default:
+		return nil, fmt.Errorf("invalid kafka backend %q: expected kafka or embedded", cfg.Kafka.Backend)
+	}
+	version, err := ParseMessageEncoding(cfg.Kafka.MessageEncoding)
+	if err != nil {
+		return nil, err
+	}
+	q, err := OpenEmbeddedQueue(cfg.Kafka.QueueDirectory, cfg.Kafka.LogTopic, cfg.Kafka.QueuePartitions, cfg.Kafka.QueueSegmentBytes)
+	if err != nil {
+		return nil, err
+	}
+	q.version = version
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			log.Info().Str("directory", q.dir).Msg("Closing embedded queue")
+			return q.Close()
+		},
+	})
+	log.Info().Str("directory", q.dir).Int("partitions", len(q.partitions)).Int("message_version", version).Msg("Embedded queue initialized")
+	return q, nil
+}
+
+// OpenEmbeddedQueue opens or creates the queue in dir, whose messages are reported as coming from
+// topic. A queue never loses partitions: when dir holds more than requested, all of them are kept.
+func OpenEmbeddedQueue(dir, topic string, partitions int, segmentBytes int64) (*EmbeddedQueue, error) {
+	if dir == "" {
+		return nil, errors.New("invalid embedded queue configuration: directory is required")
+	}
+	if partitions < 1 || segmentBytes < 1 {
+		return nil, fmt.Errorf("invalid embedded queue configuration: %d partitions of %d-byte segments", partitions, segmentBytes)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create embedded queue directory: %w", err)
+	}
+	existing, err := countPartitions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if existing > partitions {
+		log.Warn().Int("configured", partitions).Int("existing", existing).Str("directory", dir).Msg("Embedded queue has more partitions than configured, keeping them all")
+		partitions = existing
+	}
+
+	q := &EmbeddedQueue{dir: dir, topic: topic, version: MessageVersionCompact}
+	for id := 0; id < partitions; id++ {
+		p, err := openPartition(filepath.Join(dir, partitionPrefix+strconv.Itoa(id)), id, segmentBytes)
+		if err != nil {
+			q.Close()
+			return nil, fmt.Errorf("failed to open embedded queue partition %d: %w", id, err)
+		}
+		q.partitions = append(q.partitions, p)
+	}
+	return q, nil
+}
+
+func countPartitions(dir string) (int, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedded queue directory: %w", err)
+	}
+	count := 0
+	for _, dirEntry := range dirEntries {
+		id, err := strconv.Atoi(strings.TrimPrefix(dirEntry.Name(), partitionPrefix))
+		if dirEntry.IsDir() && strings.HasPrefix(dirEntry.Name(), partitionPrefix) && err == nil {
+			count = max(count, id+1)
+		}
+	}
+	return count, nil
+}
+
+func (q *EmbeddedQueue) Produce(ctx context.Context, logs []model.LogEntry) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	partitionIDs := make([]int, len(q.partitions))
+	for i := range partitionIDs {
+		partitionIDs[i] = i
+	}
+	records := make([][]walRecord, len(q.partitions))
+	indexes := make([][]int, len(q.partitions)) // Position in logs of each record
+	now := time.Now()
+	for i, entry := range logs {
+		value, err := EncodeLogEntry(entry, q.version)
+		if err != nil {
+			// Retrying cannot fix this, so the entry is dropped rather than reported as undelivered
+			log.Error().Err(err).Interface("log", entry).Msg("Failed to marshal log entry for the embedded queue")
+			continue
+		}
+		key := []byte(entry.Application)
+		partition := q.balancer.Balance(kafka.Message{Key: key}, partitionIDs...)
+		records[partition] = append(records[partition], walRecord{time: now, key: key, value: value})
+		indexes[partition] = append(indexes[partition], i)
+	}
+
+	var deliveryErr *DeliveryError
+	for id, partitionRecords := range records {
+		if len(partitionRecords) == 0 {
+			continue
+		}
+		if err := q.partitions[id].append(partitionRecords); err != nil {
+			log.Error().Err(err).Int("partition", id).Int("message_count", len(partitionRecords)).Msg("Failed to append messages to the embedded queue")
+			if deliveryErr == nil {
+				deliveryErr = &DeliveryError{Failed: make([]bool, len(logs)), Err: err}
+			}
+			for _, i := range indexes[id] {
+				deliveryErr.Failed[i] = true
+			}
+		}
+	}
+	if deliveryErr != nil {
+		return deliveryErr
+	}
+	log.Debug().Int("message_count", len(logs)).Msg("Successfully appended messages to the embedded queue")
+	return nil
+}
+
+// Consume runs handle for every partition, which are all assigned to this process.
+func (q *EmbeddedQueue) Consume(ctx context.Context, handle func(ctx context.Context, partition PartitionConsumer)) error {
+	var wg sync.WaitGroup
+	for _, p := range q.partitions {
+		wg.Add(1)
+		go func(p *walPartition) {
+			defer wg.Done()
+			consumer := &walPartitionConsumer{partition: p, topic: q.topic, offset: p.committedOffset()}
+			defer consumer.close()
+			handle(ctx, consumer)
+		}(p)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Ping checks that no partition is closed and that their last segments are still on disk.
+func (q *EmbeddedQueue) Ping(ctx context.Context) error {
+	for _, p := range q.partitions {
+		if err := p.ping(); err != nil {
+			return fmt.Errorf("embedded queue partition %d: %w", p.id, err)
+		}
+	}
+	return nil
+}
+
+func (q *EmbeddedQueue) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		for _, p := range q.partitions {
+			err = errors.Join(err, p.close())
+		}
+	})
+	return err
+}
+
+// walRecord is one message of a partition. Its offset is the base offset of its segment plus its
+// position there.
+type walRecord struct {
+	time  time.Time
+	key   []byte
+	value []byte
+}
+
+func (r walRecord) encodedSize() int {
+	return recordHeaderSize + len(r.key) + len(r.value)
+}
+
+// appendTo writes the record as CRC-32C | length | timestamp | key length | key | value, with the
+// CRC covering everything after itself and the length everything after itself.
+func (r walRecord) appendTo(buf []byte) []byte {
+	start := len(buf)
+	buf = binary.BigEndian.AppendUint32(buf, 0)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(r.encodedSize()-8))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.time.UnixNano()))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(r.key)))
+	buf = append(buf, r.key...)
+	buf = append(buf, r.value...)
+	binary.BigEndian.PutUint32(buf[start:], crc32.Checksum(buf[start+4:], castagnoli))
+	return buf
+}
+
+// readRecordAt reads the record at pos and returns its size. It returns io.EOF when pos is the end
+// of the file, and errTornRecord when the record is incomplete or damaged.
+func readRecordAt(f *os.File, pos int64) (walRecord, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	n, err := f.ReadAt(header, pos)
+	if n == 0 && errors.Is(err, io.EOF) {
+		return walRecord{}, 0, io.EOF
+	}
+	if n < recordHeaderSize {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = errTornRecord
+		}
+		return walRecord{}, 0, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[4:8]))
+	keyLength := int64(binary.BigEndian.Uint32(header[16:20]))
+	if length < recordHeaderSize-8 || length > maxRecordSize || keyLength > length-(recordHeaderSize-8) {
+		return walRecord{}, 0, errTornRecord
+	}
+
+	body := make([]byte, length-(recordHeaderSize-8))
+	if n, err := f.ReadAt(body, pos+recordHeaderSize); n < len(body) {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = errTornRecord
+		}
+		return walRecord{}, 0, err
+	}
+	crc := crc32.Update(crc32.Checksum(header[4:], castagnoli), castagnoli, body)
+	if crc != binary.BigEndian.Uint32(header[0:4]) {
+		return walRecord{}, 0, errTornRecord
+	}
+	return walRecord{
+		time:  time.Unix(0, int64(binary.BigEndian.Uint64(header[8:16]))),
+		key:   body[:keyLength],
+		value: body[keyLength:],
+	}, 8 + length, nil
+}
+
+// walPartition is a directory of segment files named after the offset of their first record, and
+// a file holding the committed offset. Records are only appended to the last segment.
+type walPartition struct {
+	id           int
+	dir          string
+	segmentBytes int64 // The last segment is rolled once it reaches this size
+
+	mu        sync.Mutex
+	bases     []int64  // Base offset of each segment, ascending
+	active    *os.File // The last segment, nil once closed
+	size      int64    // Bytes in the last segment
+	next      int64    // Offset of the next record appended
+	committed int64    // Offset of the first record not committed
+	appended  chan struct{}
+}
+
+func openPartition(dir string, id int, segmentBytes int64) (*walPartition, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	p := &walPartition{id: id, dir: dir, segmentBytes: segmentBytes, appended: make(chan struct{})}
+
+	committed, hasCommit, err := p.readCommitted()
+	if err != nil {
+		return nil, err
+	}
+	p.bases, err = p.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.bases) == 0 {
+		if err := p.createSegment(committed); err != nil {
+			return nil, err
+		}
+	} else if err := p.recoverActive(); err != nil {
+		return nil, err
+	}
+
+	if !hasCommit || committed < p.bases[0] {
+		committed = p.bases[0]
+	}
+	if committed > p.next {
+		log.Warn().Int("partition", id).Int64("committed", committed).Int64("end", p.next).Msg("Embedded queue commit is past the end of its partition, resetting it")
+		committed = p.next
+	}
+	p.committed = committed
+	return p, nil
+}
+
+func (p *walPartition) segmentPath(base int64) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%020d%s", base, segmentSuffix))
+}
+
+func (p *walPartition) listSegments() ([]int64, error) {
+	dirEntries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, err
+	}
+	var bases []int64
+	for _, dirEntry := range dirEntries {
+		name, ok := strings.CutSuffix(dirEntry.Name(), segmentSuffix)
+		if !ok || dirEntry.IsDir() {
+			continue
+		}
+		base, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	return bases, nil
+}
+
+// recoverActive opens the last segment and cuts off what a crash left of a record being appended.
+func (p *walPartition) recoverActive() error {
+	base := p.bases[len(p.bases)-1]
+	f, err := os.OpenFile(p.segmentPath(base), os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	var pos, count int64
+	for {
+		_, size, err := readRecordAt(f, pos)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, errTornRecord) {
+			log.Warn().Int("partition", p.id).Int64("offset", base+count).Int64("position", pos).Msg("Truncating torn record at the end of the embedded queue")
+			if err := f.Truncate(pos); err != nil {
+				f.Close()
+				return err
+			}
+			break
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+		pos += size
+		count++
+	}
+	p.active, p.size, p.next = f, pos, base+count
+	return nil
+}
+
+// createSegment makes base the last segment.
+func (p *walPartition) createSegment(base int64) error {
+	f, err := os.OpenFile(p.segmentPath(base), os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := syncDir(p.dir); err != nil {
+		f.Close()
+		return err
+	}
+	p.bases = append(p.bases, base)
+	p.active, p.size, p.next = f, 0, base
+	return nil
+}
+
+func (p *walPartition) append(records []walRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return ErrQueueClosed
+	}
+	if p.size >= p.segmentBytes {
+		if err := p.roll(); err != nil {
+			return err
+		}
+	}
+
+	size := 0
+	for _, record := range records {
+		size += record.encodedSize()
+	}
+	buf := make([]byte, 0, size)
+	for _, record := range records {
+		buf = record.appendTo(buf)
+	}
+	if _, err := p.active.Write(buf); err != nil {
+		// Drop what was written, so the records can be appended again
+		p.active.Truncate(p.size)
+		return err
+	}
+	if err := p.active.Sync(); err != nil {
+		p.active.Truncate(p.size)
+		return err
+	}
+
+	p.size += int64(len(buf))
+	p.next += int64(len(records))
+	close(p.appended)
+	p.appended = make(chan struct{})
+	return nil
+}
+
+// roll starts a new segment after the last one, which is complete and synced.
+func (p *walPartition) roll() error {
+	if err := p.active.Sync(); err != nil {
+		return err
+	}
+	previous := p.active
+	if err := p.createSegment(p.next); err != nil {
+		return err
+	}
+	if err := previous.Close(); err != nil {
+		log.Warn().Err(err).Int("partition", p.id).Msg("Failed to close embedded queue segment")
+	}
+	return nil
+}
+
+func (p *walPartition) committedOffset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.committed
+}
+
+func (p *walPartition) readCommitted() (int64, bool, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, commitFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid embedded queue commit file: %w", err)
+	}
+	return offset, true, nil
+}
+
+// commit records offset as the first record not committed, then deletes the segments before it.
+func (p *walPartition) commit(offset int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return ErrQueueClosed
+	}
+	if offset <= p.committed {
+		return nil
+	}
+	if offset > p.next {
+		return fmt.Errorf("invalid commit of offset %d past the end %d of partition %d", offset, p.next, p.id)
+	}
+
+	path := filepath.Join(p.dir, commitFileName)
+	if err := writeFileSync(path+".tmp", []byte(strconv.FormatInt(offset, 10))); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	if err := syncDir(p.dir); err != nil {
+		return err
+	}
+	p.committed = offset
+
+	for len(p.bases) > 1 && p.bases[1] <= offset {
+		if err := os.Remove(p.segmentPath(p.bases[0])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Int("partition", p.id).Int64("base_offset", p.bases[0]).Msg("Failed to delete committed embedded queue segment")
+			break
+		}
+		p.bases = p.bases[1:]
+	}
+	return nil
+}
+
+func (p *walPartition) ping() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return ErrQueueClosed
+	}
+	_, err := os.Stat(p.segmentPath(p.bases[len(p.bases)-1]))
+	return err
+}
+
+func (p *walPartition) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return nil
+	}
+	err := errors.Join(p.active.Sync(), p.active.Close())
+	p.active = nil
+	close(p.appended) // Wakes fetches waiting for records
+	return err
+}
+
+// walPartitionConsumer reads a partition from its committed offset on.
+type walPartitionConsumer struct {
+	partition *walPartition
+	topic     string
+	offset    int64    // Offset of the next record fetched
+	segment   *os.File // Segment holding offset, opened on the first fetch
+	base      int64    // Base offset of segment
+	pos       int64    // Position of offset in segment
+}
+
+func (c *walPartitionConsumer) Partition() int {
+	return c.partition.id
+}
+
+func (c *walPartitionConsumer) FetchMessage(ctx context.Context) (*model.LogEntry, kafka.Message, error) {
+	highWaterMark, err := c.wait(ctx)
+	if err != nil {
+		return nil, kafka.Message{}, err
+	}
+	record, offset, err := c.read()
+	if err != nil {
+		log.Error().Err(err).Int("partition", c.partition.id).Int64("offset", c.offset).Msg("Failed to read from the embedded queue")
+		return nil, kafka.Message{}, err
+	}
+	msg := kafka.Message{
+		Topic:         c.topic,
+		Partition:     c.partition.id,
+		Offset:        offset,
+		HighWaterMark: highWaterMark,
+		Key:           record.key,
+		Value:         record.value,
+		Time:          record.time,
+	}
+	log.Debug().Int("partition", msg.Partition).Int64("offset", msg.Offset).Msg("Fetched message from the embedded queue")
+	logEntry, err := DecodeLogEntry(msg.Value)
+	if err != nil {
+		log.Error().Err(err).Int("partition", msg.Partition).Int64("offset", msg.Offset).Msg("Failed to unmarshal embedded queue message value")
+		return nil, msg, err
+	}
+	return logEntry, msg, nil
+}
+
+// wait returns once the record at c.offset is appended, with the offset of the next record appended.
+func (c *walPartitionConsumer) wait(ctx context.Context) (int64, error) {
+	for {
+		c.partition.mu.Lock()
+		next, closed, appended := c.partition.next, c.partition.active == nil, c.partition.appended
+		c.partition.mu.Unlock()
+		switch {
+		case closed:
+			return 0, ErrQueueClosed
+		case c.offset < next:
+			return next, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-appended:
+		}
+	}
+}
+
+// read returns the appended record at c.offset, or the first one after it that is readable, and
+// its offset. Records of a damaged segment are skipped up to the next segment.
+func (c *walPartitionConsumer) read() (walRecord, int64, error) {
+	for {
+		if c.segment == nil {
+			if err := c.open(); err != nil {
+				return walRecord{}, 0, err
+			}
+		}
+		record, size, err := readRecordAt(c.segment, c.pos)
+		if err == nil {
+			offset := c.offset
+			c.offset++
+			c.pos += size
+			return record, offset, nil
+		}
+		if !errors.Is(err, io.EOF) && !errors.Is(err, errTornRecord) {
+			return walRecord{}, 0, err
+		}
+
+		// The segment ended: the record is the first of a later one
+		next, ok := c.nextBase()
+		if !ok {
+			return walRecord{}, 0, fmt.Errorf("embedded queue partition %d has no record at offset %d: %w", c.partition.id, c.offset, err)
+		}
+		if errors.Is(err, errTornRecord) {
+			log.Error().Int("partition", c.partition.id).Int64("offset", c.offset).Int64("skipped", next-c.offset).Msg("Skipping damaged records of the embedded queue")
+		}
+		c.close()
+		c.offset = next
+	}
+}
+
+// nextBase returns the base offset of the segment following the open one.
+func (c *walPartitionConsumer) nextBase() (int64, bool) {
+	c.partition.mu.Lock()
+	defer c.partition.mu.Unlock()
+	for _, base := range c.partition.bases {
+		if base > c.base {
+			return base, true
+		}
+	}
+	return 0, false
+}
+
+// open opens the segment holding c.offset and finds the offset's position in it.
+func (c *walPartitionConsumer) open() error {
+	c.partition.mu.Lock()
+	bases := c.partition.bases
+	c.partition.mu.Unlock()
+	i := sort.Search(len(bases), func(i int) bool { return bases[i] > c.offset }) - 1
+	if i < 0 {
+		// Segments before the committed offset are deleted, and the consumer never goes back before it
+		return fmt.Errorf("embedded queue partition %d no longer holds offset %d", c.partition.id, c.offset)
+	}
+
+	f, err := os.Open(c.partition.segmentPath(bases[i]))
+	if err != nil {
+		return err
+	}
+	c.segment, c.base, c.pos = f, bases[i], 0
+	for offset := c.base; offset < c.offset; offset++ {
+		_, size, err := readRecordAt(f, c.pos)
+		if err != nil {
+			return err
+		}
+		c.pos += size
+	}
+	return nil
+}
+
+func (c *walPartitionConsumer) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	next := msgs[len(msgs)-1].Offset + 1
+	if err := c.partition.commit(next); err != nil {
+		log.Error().Err(err).Int("partition", c.partition.id).Int("count", len(msgs)).Msg("Failed to commit embedded queue messages")
+		return err
+	}
+	log.Debug().Int("partition", c.partition.id).Int("count", len(msgs)).Int64("last_offset", next-1).Msg("Committed embedded queue messages")
+	return nil
+}
+
+func (c *walPartitionConsumer) close() {
+	if c.segment != nil {
+		c.segment.Close()
+		c.segment = nil
+	}
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return errors.Join(f.Sync(), f.Close())
+}
+
+// syncDir makes the creation, renaming and removal of files in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
@@ -0,0 +1,129 @@
+package kafka_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	kafkaGo "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/kafka"
+	"skeleton-internship-backend/internal/model"
+)
+
+// fetchAll fetches from every partition until none has a message for a while, committing what it fetched if commit is set.
+func fetchAll(t *testing.T, q *kafka.EmbeddedQueue, commit bool) map[string]kafkaGo.Message {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	fetched := make(map[string]kafkaGo.Message)
+	done := make(chan error)
+	go func() {
+		done <- q.Consume(ctx, func(ctx context.Context, partition kafka.PartitionConsumer) {
+			for {
+				fetchCtx, cancelFetch := context.WithTimeout(ctx, 200*time.Millisecond)
+				entry, msg, err := partition.FetchMessage(fetchCtx)
+				cancelFetch()
+				if err != nil {
+					return
+				}
+				assert.Equal(t, partition.Partition(), msg.Partition)
+				mu.Lock()
+				fetched[entry.ID] = msg
+				mu.Unlock()
+				// The handler runs in its own goroutine, where require cannot stop the test
+				if commit && !assert.NoError(t, partition.CommitMessages(ctx, msg)) {
+					return
+				}
+			}
+		})
+	}()
+	<-done
+	return fetched
+}
+
+func produceEntries(t *testing.T, q *kafka.EmbeddedQueue, from, to int) {
+	t.Helper()
+	var entries []model.LogEntry
+	for i := from; i < to; i++ {
+		entries = append(entries, model.LogEntry{
+			ID:          fmt.Sprint(i),
+			Application: fmt.Sprintf("application_%d", i%5),
+			Content:     "line",
+			Raw:         "INFO x: line",
+		})
+	}
+	require.NoError(t, q.Produce(context.Background(), entries))
+}
+
+func TestEmbeddedQueue_RedeliversUncommitted(t *testing.T) {
+	dir := t.TempDir()
+	q, err := kafka.OpenEmbeddedQueue(dir, "log_entries", 3, 512)
+	require.NoError(t, err)
+	produceEntries(t, q, 0, 50)
+
+	fetched := fetchAll(t, q, false)
+	require.Len(t, fetched, 50)
+	for id, msg := range fetched {
+		assert.Equal(t, "log_entries", msg.Topic)
+		// Entries of an application stay in one partition
+		other := fetched[fmt.Sprint((atoi(t, id)+5)%50)]
+		assert.Equal(t, msg.Partition, other.Partition)
+	}
+	require.NoError(t, q.Close())
+
+	// Nothing was committed, so everything is fetched again, then nothing once committed
+	q, err = kafka.OpenEmbeddedQueue(dir, "log_entries", 3, 512)
+	require.NoError(t, err)
+	produceEntries(t, q, 50, 60)
+	assert.Len(t, fetchAll(t, q, true), 60)
+	require.NoError(t, q.Close())
+
+	q, err = kafka.OpenEmbeddedQueue(dir, "log_entries", 3, 512)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Empty(t, fetchAll(t, q, true))
+
+	// Committed segments are deleted, the last one of each partition is kept
+	segments, err := filepath.Glob(filepath.Join(dir, "partition-*", "*.wal"))
+	require.NoError(t, err)
+	assert.Len(t, segments, 3)
+}
+
+func TestEmbeddedQueue_TruncatesTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	q, err := kafka.OpenEmbeddedQueue(dir, "log_entries", 1, 1<<20)
+	require.NoError(t, err)
+	produceEntries(t, q, 0, 3)
+	require.NoError(t, q.Close())
+
+	// A crash in the middle of an append leaves part of a record
+	segment := filepath.Join(dir, "partition-0", fmt.Sprintf("%020d.wal", 0))
+	f, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 2, 0, 0, 0, 90, 0})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	q, err = kafka.OpenEmbeddedQueue(dir, "log_entries", 1, 1<<20)
+	require.NoError(t, err)
+	defer q.Close()
+	produceEntries(t, q, 3, 5)
+	fetched := fetchAll(t, q, true)
+	assert.Len(t, fetched, 5)
+	assert.Equal(t, int64(4), fetched["4"].Offset)
+}
+
+func atoi(t *testing.T, s string) int {
+	var i int
+	_, err := fmt.Sscan(s, &i)
+	require.NoError(t, err)
+	return i
+}
@@ -2,10 +2,12 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"skeleton-internship-backend/config"
 	"skeleton-internship-backend/internal/model"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
@@ -13,32 +15,88 @@ import (
 )
 
 type LogProducer interface {
+	// Produce returns once every entry is acknowledged by all in-sync replicas. When only some are,
+	// the error is a *DeliveryError telling which.
 	Produce(ctx context.Context, logs []model.LogEntry) error
+	// Ping tells whether log entries can be produced right now, for the readiness check.
+	Ping(ctx context.Context) error
 	Close() error
 }
 
+// DeliveryError reports a Produce call in which some entries were not acknowledged.
+type DeliveryError struct {
+	Failed []bool // Indexed like the entries passed to Produce
+	Err    error
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("%d of %d log entries not delivered: %v", e.failedCount(), len(e.Failed), e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DeliveryError) failedCount() int {
+	count := 0
+	for _, failed := range e.Failed {
+		if failed {
+			count++
+		}
+	}
+	return count
+}
+
 type kafkaLogProducer struct {
-	writer *kafka.Writer
-	topic  string
+	writer  *kafka.Writer
+	dialer  *kafka.Dialer
+	brokers []string
+	topic   string
+	version int // Message version entries are encoded in
+}
+
+// NewLogProducer returns the embedded queue when KAFKA_BACKEND selects it, else a Kafka producer.
+func NewLogProducer(lc fx.Lifecycle, cfg *config.Config, security *Security, queue *EmbeddedQueue) (LogProducer, error) {
+	if queue != nil {
+		return queue, nil
+	}
+	return NewKafkaLogProducer(lc, cfg, security)
 }
 
-func NewKafkaLogProducer(lc fx.Lifecycle, cfg *config.Config) (LogProducer, error) {
+func NewKafkaLogProducer(lc fx.Lifecycle, cfg *config.Config, security *Security) (LogProducer, error) {
 	if len(cfg.Kafka.Brokers) == 0 || cfg.Kafka.LogTopic == "" {
 		log.Error().Msg("Kafka brokers or log topic is not configured.")
 		return nil, errors.New("kafka configuration missing")
 	}
+	version, err := ParseMessageEncoding(cfg.Kafka.MessageEncoding)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := parseCompression(cfg.Kafka.Compression)
+	if err != nil {
+		return nil, err
+	}
+	dialer := security.Dialer()
 	writer := kafka.NewWriter(kafka.WriterConfig{
 
-		Brokers:      cfg.Kafka.Brokers,
-		Topic:        cfg.Kafka.LogTopic,
-		Balancer:     &kafka.LeastBytes{},
-		BatchSize:    cfg.LogProcessor.BatchSize,
-		BatchTimeout: cfg.LogProcessor.MaxBatchWait,
-		Async:        true,
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.LogTopic,
+		Dialer:  dialer,
+		// Entries are keyed by application, so each application's entries stay in one partition, in order
+		Balancer:  &kafka.Hash{},
+		BatchSize: cfg.LogProcessor.BatchSize,
+		// Writes are synchronous, so a partial batch only waits for messages of the same call
+		BatchTimeout: 10 * time.Millisecond,
+		// File offsets only advance for acknowledged entries, so wait for all in-sync replicas
+		RequiredAcks: int(kafka.RequireAll),
 	})
+	writer.Compression = compression
 	p := &kafkaLogProducer{
-		writer: writer,
-		topic:  cfg.Kafka.LogTopic,
+		writer:  writer,
+		dialer:  dialer,
+		brokers: cfg.Kafka.Brokers,
+		topic:   cfg.Kafka.LogTopic,
+		version: version,
 	}
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
@@ -46,7 +104,7 @@ func NewKafkaLogProducer(lc fx.Lifecycle, cfg *config.Config) (LogProducer, erro
 			return p.Close()
 		},
 	})
-	log.Info().Strs("brokers", cfg.Kafka.Brokers).Str("topic", cfg.Kafka.LogTopic).Msg("Kafka producer initialized")
+	log.Info().Strs("brokers", cfg.Kafka.Brokers).Str("topic", cfg.Kafka.LogTopic).Int("message_version", version).Stringer("compression", compression).Msg("Kafka producer initialized")
 	return p, nil
 }
 
@@ -54,20 +112,23 @@ func (p *kafkaLogProducer) Produce(ctx context.Context, logs []model.LogEntry) e
 	if len(logs) == 0 {
 		return nil
 	}
-	messages := make([]kafka.Message, len(logs))
+	messages := make([]kafka.Message, 0, len(logs))
+	indexes := make([]int, 0, len(logs)) // Position in logs of each message
 
 	for i, LogEntry := range logs {
 		key := []byte(LogEntry.Application)
-		value, err := json.Marshal(LogEntry)
+		value, err := EncodeLogEntry(LogEntry, p.version)
 
 		if err != nil {
-			log.Error().Err(err).Interface("log", logs).Msg("Failed to marshal log entry for Kafka")
+			// Retrying cannot fix this, so the entry is dropped rather than reported as undelivered
+			log.Error().Err(err).Interface("log", LogEntry).Msg("Failed to marshal log entry for Kafka")
 			continue
 		}
-		messages[i] = kafka.Message{
+		messages = append(messages, kafka.Message{
 			Key:   key,
 			Value: value,
-		}
+		})
+		indexes = append(indexes, i)
 	}
 	if len(messages) == 0 {
 		log.Warn().Msg("No valid messages to produce.")
@@ -77,7 +138,18 @@ func (p *kafkaLogProducer) Produce(ctx context.Context, logs []model.LogEntry) e
 	err := p.writer.WriteMessages(ctx, messages...)
 	if err != nil {
 		log.Error().Err(err).Int("message_count", len(messages)).Msg("Failed to write messages to Kafka")
-		return err
+		deliveryErr := &DeliveryError{Failed: make([]bool, len(logs)), Err: err}
+		var writeErrs kafka.WriteErrors
+		if errors.As(err, &writeErrs) && len(writeErrs) == len(messages) {
+			for i, writeErr := range writeErrs {
+				deliveryErr.Failed[indexes[i]] = writeErr != nil
+			}
+		} else {
+			for _, i := range indexes {
+				deliveryErr.Failed[i] = true
+			}
+		}
+		return deliveryErr
 	}
 
 	log.Debug().Int("message_count", len(messages)).Str("topic", p.topic).Msg("Successfully produced messages to Kafka")
@@ -85,6 +157,45 @@ func (p *kafkaLogProducer) Produce(ctx context.Context, logs []model.LogEntry) e
 	return nil
 }
 
+// Ping connects to the first reachable broker and reads the partitions of the log topic.
+func (p *kafkaLogProducer) Ping(ctx context.Context) error {
+	if len(p.brokers) == 0 {
+		return errors.New("no kafka brokers configured")
+	}
+	var err error
+	for _, broker := range p.brokers {
+		var conn *kafka.Conn
+		conn, err = p.dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			continue
+		}
+		var partitions []kafka.Partition
+		partitions, err = conn.ReadPartitions(p.topic)
+		conn.Close()
+		if err == nil && len(partitions) == 0 {
+			err = fmt.Errorf("topic %s has no partitions", p.topic)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no kafka broker reachable: %w", err)
+}
+
 func (p *kafkaLogProducer) Close() error {
 	return p.writer.Close()
 }
+
+// parseCompression returns the writer compression named by KAFKA_COMPRESSION. Readers decompress
+// whatever codec a message was written with, so changing it needs no consumer rollout.
+func parseCompression(name string) (kafka.Compression, error) {
+	var compression kafka.Compression
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return compression, nil
+	}
+	if err := compression.UnmarshalText([]byte(name)); err != nil {
+		return compression, fmt.Errorf("invalid kafka compression %q: expected none, gzip, snappy, lz4 or zstd", name)
+	}
+	return compression, nil
+}
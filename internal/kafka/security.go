@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"skeleton-internship-backend/config"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// verifyTimeout bounds the connection made at startup to check the security settings.
+const verifyTimeout = 10 * time.Second
+
+// Security is how connections to the brokers are encrypted and authenticated, per
+// KAFKA_SECURITY_PROTOCOL. Every reader, writer and client of the package connects with it.
+type Security struct {
+	protocol  string
+	tls       *tls.Config    // nil for PLAINTEXT and SASL_PLAINTEXT
+	mechanism sasl.Mechanism // nil for PLAINTEXT and SSL
+}
+
+// NewSecurity loads the certificates and credentials of cfg.Kafka, then connects to a broker so that
+// a rejected certificate or password stops the service at startup instead of failing every write.
+// Unreachable brokers are only logged, since Kafka may come up after the service.
+func NewSecurity(cfg *config.Config) (*Security, error) {
+	security, err := newSecurity(&cfg.Kafka)
+	if err != nil {
+		return nil, err
+	}
+	if security.tls == nil && security.mechanism == nil {
+		return security, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+	if err := security.verify(ctx, cfg.Kafka.Brokers); err != nil {
+		return nil, err
+	}
+	return security, nil
+}
+
+func newSecurity(cfg *config.KafkaConfig) (*Security, error) {
+	protocol := strings.ToUpper(strings.TrimSpace(cfg.SecurityProtocol))
+	if protocol == "" {
+		protocol = "PLAINTEXT"
+	}
+	security := &Security{protocol: protocol}
+
+	var useTLS, useSASL bool
+	switch protocol {
+	case "PLAINTEXT":
+	case "SSL":
+		useTLS = true
+	case "SASL_PLAINTEXT":
+		useSASL = true
+	case "SASL_SSL":
+		useTLS, useSASL = true, true
+	default:
+		return nil, fmt.Errorf("invalid kafka security protocol %q: expected PLAINTEXT, SSL, SASL_PLAINTEXT or SASL_SSL", cfg.SecurityProtocol)
+	}
+
+	if useTLS {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		security.tls = tlsConfig
+	} else if cfg.TLSCAFile != "" || cfg.TLSCertFile != "" {
+		log.Warn().Str("protocol", protocol).Msg("Kafka TLS files are ignored without the SSL or SASL_SSL security protocol")
+	}
+
+	if useSASL {
+		mechanism, err := newSASLMechanism(cfg)
+		if err != nil {
+			return nil, err
+		}
+		security.mechanism = mechanism
+	}
+	return security, nil
+}
+
+func newTLSConfig(cfg *config.KafkaConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid kafka CA file %s: no PEM certificate found", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, errors.New("invalid kafka TLS configuration: the client certificate and key files go together")
+	}
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func newSASLMechanism(cfg *config.KafkaConfig) (sasl.Mechanism, error) {
+	if cfg.SASLUsername == "" {
+		return nil, errors.New("invalid kafka SASL configuration: username is required")
+	}
+	switch strings.ToUpper(strings.TrimSpace(cfg.SASLMechanism)) {
+	case "PLAIN":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	default:
+		return nil, fmt.Errorf("invalid kafka SASL mechanism %q: expected PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512", cfg.SASLMechanism)
+	}
+}
+
+// Dialer is for readers and consumer groups.
+func (s *Security) Dialer() *kafka.Dialer {
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           s.tls,
+		SASLMechanism: s.mechanism,
+	}
+}
+
+// Transport is for writers and clients.
+func (s *Security) Transport() *kafka.Transport {
+	return &kafka.Transport{
+		DialTimeout: 10 * time.Second,
+		TLS:         s.tls,
+		SASL:        s.mechanism,
+	}
+}
+
+// verify connects to the first reachable broker, which goes through the TLS and SASL handshakes.
+func (s *Security) verify(ctx context.Context, brokers []string) error {
+	dialer := s.Dialer()
+	var unreachable error
+	for _, broker := range brokers {
+		conn, err := dialer.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			conn.Close()
+			log.Info().Str("broker", broker).Str("protocol", s.protocol).Msg("Kafka security settings verified")
+			return nil
+		}
+		if isHandshakeError(err) {
+			return fmt.Errorf("kafka %s handshake with %s failed: %w", s.protocol, broker, err)
+		}
+		unreachable = err
+	}
+	log.Warn().Err(unreachable).Str("protocol", s.protocol).Msg("No Kafka broker reachable to verify the security settings")
+	return nil
+}
+
+// isHandshakeError tells a rejected certificate or credential apart from a broker that is down.
+func isHandshakeError(err error) bool {
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr == kafka.SASLAuthenticationFailed || kafkaErr == kafka.UnsupportedSASLMechanism || kafkaErr == kafka.IllegalSASLState
+	}
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &certErr), errors.As(err, &recordErr):
+		return true
+	case errors.As(err, &opErr) && opErr.Op == "remote error":
+		// TLS alerts sent by the broker, e.g. when it rejects the client certificate
+		return true
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package metrics
 import (
 	"regexp"
 	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+	"strconv"
 	"sync"
 
 	"github.com/rs/zerolog/log"
@@ -41,6 +43,10 @@ func (e *sparkLogExtractor) ExtractMetricEvents(logEntry *model.LogEntry) []mode
 		"level":     logEntry.Level,
 		"component": logEntry.Component,
 	}
+	if logEntry.EventID != "" {
+		logEventTags["event_id"] = logEntry.EventID
+	}
+	addContainerTags(logEventTags, logEntry)
 	if logEntry.Level == "UNKNOWN" || logEntry.Component == "UNKNOWN" || logEntry.Component == "ORPHAN" {
 		logEventTags["parse_status"] = "failed_or_orphan"
 	}
@@ -50,6 +56,7 @@ func (e *sparkLogExtractor) ExtractMetricEvents(logEntry *model.LogEntry) []mode
 		MetricName:  "log_event",
 		Application: app,
 		Tags:        logEventTags,
+		EntryID:     logEntry.ID,
 	})
 
 	isError := logEntry.Level == "ERROR"
@@ -59,15 +66,30 @@ func (e *sparkLogExtractor) ExtractMetricEvents(logEntry *model.LogEntry) []mode
 	}
 
 	if isError {
+		errorEventTags := map[string]string{
+			"component": logEntry.Component,
+			"level":     logEntry.Level,
+		}
+		// Group by fingerprint rather than raw content so the same error on different executors collapses
+		// Messages come from Kafka, so a trace without exceptions is possible and is treated as no trace
+		if logEntry.StackTrace != nil && len(logEntry.StackTrace.Exceptions) > 0 {
+			errorEventTags["error_key"] = logEntry.StackTrace.Fingerprint
+			exceptions := logEntry.StackTrace.Exceptions
+			errorEventTags["exception_class"] = exceptions[0].Class
+			errorEventTags["root_cause"] = exceptions[len(exceptions)-1].Class
+		} else {
+			errorEventTags["error_key"] = parser.MessageFingerprint(logEntry.Content)
+		}
+		if logEntry.EventID != "" {
+			errorEventTags["event_id"] = logEntry.EventID
+		}
+		addContainerTags(errorEventTags, logEntry)
 		events = append(events, model.MetricEvent{
 			Time:        ts,
 			MetricName:  "error_event",
 			Application: app,
-			Tags: map[string]string{
-				"component": logEntry.Component,
-				"level":     logEntry.Level,
-				"error_key": logEntry.Content,
-			},
+			Tags:        errorEventTags,
+			EntryID:     logEntry.ID,
 		})
 	}
 	if len(events) > 0 {
@@ -75,3 +97,14 @@ func (e *sparkLogExtractor) ExtractMetricEvents(logEntry *model.LogEntry) []mode
 	}
 	return events
 }
+
+// addContainerTags tags events from container log files with the container and its application attempt.
+func addContainerTags(tags map[string]string, logEntry *model.LogEntry) {
+	if logEntry.ContainerID == "" {
+		return
+	}
+	tags["container_id"] = logEntry.ContainerID
+	if logEntry.Attempt > 0 {
+		tags["attempt"] = strconv.Itoa(logEntry.Attempt)
+	}
+}
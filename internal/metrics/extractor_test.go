@@ -0,0 +1,47 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/metrics"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+)
+
+func TestExtractMetricEvents_StackTrace(t *testing.T) {
+	extractor := metrics.NewSparkLogExtractor()
+
+	var entry model.LogEntry
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"application": "application_1485248649253_0186",
+		"level": "ERROR",
+		"component": "executor.Executor",
+		"content": "Exception in task 0.0",
+		"stack_trace": {"fingerprint": "abc", "exceptions": [
+			{"class": "org.apache.spark.SparkException"},
+			{"class": "java.io.IOException"}
+		]}
+	}`), &entry))
+	events := extractor.ExtractMetricEvents(&entry)
+	require.Len(t, events, 2)
+	assert.Equal(t, "error_event", events[1].MetricName)
+	assert.Equal(t, "abc", events[1].Tags["error_key"])
+	assert.Equal(t, "org.apache.spark.SparkException", events[1].Tags["exception_class"])
+	assert.Equal(t, "java.io.IOException", events[1].Tags["root_cause"])
+
+	// A message with an empty trace is grouped by its content instead
+	entry = model.LogEntry{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"level": "ERROR",
+		"content": "Exception in task 0.0",
+		"stack_trace": {"fingerprint": "abc", "exceptions": []}
+	}`), &entry))
+	events = extractor.ExtractMetricEvents(&entry)
+	require.Len(t, events, 2)
+	assert.Equal(t, parser.MessageFingerprint("Exception in task 0.0"), events[1].Tags["error_key"])
+	assert.NotContains(t, events[1].Tags, "exception_class")
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// Application statuses. RUNNING means no final status has been logged yet.
+const (
+	AppStatusRunning   = "RUNNING"
+	AppStatusSucceeded = "SUCCEEDED"
+	AppStatusFailed    = "FAILED"
+	AppStatusKilled    = "KILLED"
+)
+
+// Container states derived from YarnAllocator messages.
+const (
+	ContainerStateRunning   = "RUNNING"
+	ContainerStateCompleted = "COMPLETED"
+	ContainerStateFailed    = "FAILED"
+	ContainerStateKilled    = "KILLED"
+)
+
+// Application is the lifecycle of one Spark-on-YARN application as reconstructed from its logs.
+type Application struct {
+	ID             string               `json:"id"`
+	Status         string               `json:"status"`
+	StartTime      *time.Time           `json:"start_time,omitempty"` // First ApplicationMaster attempt start
+	EndTime        *time.Time           `json:"end_time,omitempty"`   // When the final status was logged
+	ExitCode       *int                 `json:"exit_code,omitempty"`
+	Diagnostics    string               `json:"diagnostics,omitempty"`
+	FirstSeen      time.Time            `json:"first_seen"` // Earliest lifecycle log line, also for apps without ApplicationMaster logs
+	LastSeen       time.Time            `json:"last_seen"`
+	ContainerCount int                  `json:"container_count"`
+	Attempts       []ApplicationAttempt `json:"attempts,omitempty"`   // Only filled in when fetching a single application
+	Containers     []ContainerInfo      `json:"containers,omitempty"` // Only filled in when fetching a single application
+}
+
+type ApplicationAttempt struct {
+	Attempt     int        `json:"attempt"`
+	AttemptID   string     `json:"attempt_id,omitempty"` // e.g. appattempt_1485248649253_0186_000002
+	Status      string     `json:"status"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	ExitCode    *int       `json:"exit_code,omitempty"`
+	Diagnostics string     `json:"diagnostics,omitempty"`
+}
+
+type ContainerInfo struct {
+	ContainerID string     `json:"container_id"`
+	Attempt     int        `json:"attempt"`
+	Host        string     `json:"host,omitempty"`
+	State       string     `json:"state"`
+	ExitStatus  *int       `json:"exit_status,omitempty"`
+	Diagnostics string     `json:"diagnostics,omitempty"`
+	LaunchTime  *time.Time `json:"launch_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	FirstSeen   time.Time  `json:"first_seen"`
+	LastSeen    time.Time  `json:"last_seen"`
+}
+
+// Kinds of ApplicationEvent.
+const (
+	AppEventAttemptStarted     = "attempt_started"
+	AppEventFinished           = "finished"
+	AppEventContainerLaunched  = "container_launched"
+	AppEventContainerCompleted = "container_completed"
+	AppEventContainerSeen      = "container_seen" // Any log line written by the container itself
+	AppEventSeen               = "seen"           // Any log line of the application, from whatever source
+)
+
+// ApplicationEvent is one lifecycle fact extracted from a log entry; the application record is built by folding these.
+type ApplicationEvent struct {
+	Kind        string
+	Application string
+	Time        time.Time
+	Attempt     int    // 0 when unknown
+	AttemptID   string // AppEventAttemptStarted only
+	ContainerID string
+	Host        string
+	Status      string // Application status for AppEventFinished, container state for AppEventContainerCompleted
+	ExitCode    *int
+	Diagnostics string
+}
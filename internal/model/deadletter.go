@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// DeadLetter is a log topic message the consumer gave up on, as kept in the dead-letter topic.
+type DeadLetter struct {
+	ID        string `json:"id"` // "<partition>-<offset>" in the dead-letter topic
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"` // The original message, unchanged
+
+	Stage    string    `json:"stage"` // DeadLetterStageDecode or DeadLetterStageStore
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failedAt"`
+
+	OriginalTopic     string `json:"originalTopic"`
+	OriginalPartition int    `json:"originalPartition"`
+	OriginalOffset    int64  `json:"originalOffset"`
+}
+
+// Stages of DeadLetter.
+const (
+	DeadLetterStageDecode = "decode" // The message is not a log entry
+	DeadLetterStageStore  = "store"  // Storing the message's batch kept failing
+)
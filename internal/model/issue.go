@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Issue groups every error event sharing an error_key fingerprint, the way Sentry groups events into issues.
+type Issue struct {
+	ID             int64     `json:"id"`
+	Fingerprint    string    `json:"fingerprint"`
+	Title          string    `json:"title"`
+	ExceptionClass string    `json:"exception_class,omitempty"`
+	Component      string    `json:"component"`
+	Level          string    `json:"level"`
+	EventID        string    `json:"event_id,omitempty"`
+	FirstSeen      time.Time `json:"first_seen"`
+	LastSeen       time.Time `json:"last_seen"`
+	Count          int64     `json:"count"`
+	Applications   []string  `json:"applications"`
+	SampleRawLog   string    `json:"sample_raw_log"` // raw_log of the most recent occurrence
+
+	// IDs of the log entries among the Count new occurrences. Each is only counted the first time it
+	// is upserted, so a redelivered batch does not count its occurrences again.
+	OccurrenceIDs []string `json:"-"`
+}
@@ -3,11 +3,39 @@ package model
 import "time"
 
 type LogEntry struct {
-	Timestamp   time.Time `json:"@timestamp"`
-	Level       string    `json:"level"`
-	Component   string    `json:"component"`
-	Content     string    `json:"content"`
-	Application string    `json:"application"`
-	SourceFile  string    `json:"source_file"`
-	Raw         string    `json:"raw_log"`
+	ID                string         `json:"id,omitempty"` // Derived from the source file and offset, stable across redeliveries
+	Timestamp         time.Time      `json:"@timestamp"`
+	TimestampInferred bool           `json:"timestamp_inferred,omitempty"` // Timestamp was inherited, not parsed from the line
+	Level             string         `json:"level"`
+	Component         string         `json:"component"`
+	Content           string         `json:"content"`
+	Application       string         `json:"application"`
+	ContainerID       string         `json:"container_id,omitempty"`
+	Attempt           int            `json:"attempt,omitempty"`  // Application attempt the container belongs to
+	Hostname          string         `json:"hostname,omitempty"` // Sending host of syslog messages
+	SourceFile        string         `json:"source_file"`
+	Raw               string         `json:"raw_log"`
+	EventID           string         `json:"event_id,omitempty"`
+	EventParams       []string       `json:"event_params,omitempty"`
+	Fields            map[string]any `json:"fields,omitempty"` // Typed values extracted from Content, e.g. executor_id, duration_ms
+	StackTrace        *StackTrace    `json:"stack_trace,omitempty"`
+}
+
+// StackTrace is a Java stack trace parsed out of a log entry's content.
+type StackTrace struct {
+	Fingerprint string          `json:"fingerprint"` // Stable across hosts and builds: line numbers, hex addresses and messages are ignored
+	Exceptions  []ExceptionInfo `json:"exceptions"`  // Outermost exception first, followed by each "Caused by"
+}
+
+type ExceptionInfo struct {
+	Class         string       `json:"class"`
+	Message       string       `json:"message,omitempty"`
+	Frames        []StackFrame `json:"frames,omitempty"`
+	OmittedFrames int          `json:"omitted_frames,omitempty"` // From "... N more"
+}
+
+type StackFrame struct {
+	Method string `json:"method"` // Fully qualified, e.g. org.apache.spark.executor.Executor$TaskRunner.run
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
 }
@@ -7,4 +7,5 @@ type MetricEvent struct {
 	MetricName  string            `json:"metric_name"`
 	Application string            `json:"application"`
 	Tags        map[string]string `json:"tags"`
+	EntryID     string            `json:"entry_id,omitempty"` // ID of the log entry it was extracted from, so it is stored once
 }
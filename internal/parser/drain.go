@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// drainTree is a fixed-depth parse tree as described in "Drain: An Online Log Parsing Approach
+// with Fixed Depth Tree" (He et al., ICWS 2017). The first level splits messages by token count,
+// the next (depth-2) levels by leading tokens, and leaves hold the clusters.
+type drainTree struct {
+	depth        int
+	simThreshold float64
+	maxChildren  int
+	root         map[int]*drainNode // Token count -> first prefix node
+}
+
+type drainNode struct {
+	children map[string]*drainNode
+	clusters []*drainCluster
+}
+
+type drainCluster struct {
+	id     string
+	tokens []string
+	size   int64
+}
+
+func newDrainTree(depth int, simThreshold float64, maxChildren int) *drainTree {
+	if depth < 3 {
+		depth = 3
+	}
+	if maxChildren < 1 {
+		maxChildren = 1
+	}
+	return &drainTree{
+		depth:        depth,
+		simThreshold: simThreshold,
+		maxChildren:  maxChildren,
+		root:         make(map[int]*drainNode),
+	}
+}
+
+func newDrainNode() *drainNode {
+	return &drainNode{children: make(map[string]*drainNode)}
+}
+
+func (c *drainCluster) template() string {
+	return strings.Join(c.tokens, " ")
+}
+
+// tokenize splits the first line of content into tokens, masking anything that contains a digit.
+func tokenize(content string) []string {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	tokens := strings.Fields(firstLine)
+	for i, token := range tokens {
+		if strings.IndexFunc(token, unicode.IsDigit) >= 0 {
+			tokens[i] = templateWildcard
+		}
+	}
+	return tokens
+}
+
+// search returns the most similar cluster in the leaf for tokens, or nil if none is close enough.
+func (t *drainTree) search(tokens []string) *drainCluster {
+	node, ok := t.root[len(tokens)]
+	if !ok {
+		return nil
+	}
+	for i := 0; i < t.prefixDepth(len(tokens)); i++ {
+		next, ok := node.children[tokens[i]]
+		if !ok {
+			next, ok = node.children[templateWildcard]
+			if !ok {
+				return nil
+			}
+		}
+		node = next
+	}
+	return t.bestMatch(node.clusters, tokens)
+}
+
+func (t *drainTree) bestMatch(clusters []*drainCluster, tokens []string) *drainCluster {
+	var best *drainCluster
+	bestSim, bestParams := -1.0, -1
+	for _, c := range clusters {
+		sim, params := similarity(c.tokens, tokens)
+		if sim > bestSim || (sim == bestSim && params > bestParams) {
+			best, bestSim, bestParams = c, sim, params
+		}
+	}
+	if best == nil || bestSim < t.simThreshold {
+		return nil
+	}
+	return best
+}
+
+// insert places a cluster into the tree, creating prefix nodes along the way.
+func (t *drainTree) insert(c *drainCluster) {
+	node, ok := t.root[len(c.tokens)]
+	if !ok {
+		node = newDrainNode()
+		t.root[len(c.tokens)] = node
+	}
+	for i := 0; i < t.prefixDepth(len(c.tokens)); i++ {
+		token := c.tokens[i]
+		if next, ok := node.children[token]; ok {
+			node = next
+			continue
+		}
+		if token != templateWildcard && len(node.children) >= t.maxChildren {
+			token = templateWildcard
+			if next, ok := node.children[token]; ok {
+				node = next
+				continue
+			}
+		}
+		next := newDrainNode()
+		node.children[token] = next
+		node = next
+	}
+	node.clusters = append(node.clusters, c)
+}
+
+func (t *drainTree) prefixDepth(tokenCount int) int {
+	return min(t.depth-2, tokenCount)
+}
+
+// similarity is the fraction of positions where template and tokens agree, ignoring wildcards.
+// The second return value is the number of wildcards, used to break ties.
+func similarity(template, tokens []string) (float64, int) {
+	if len(template) == 0 {
+		return 1, 0
+	}
+	same, params := 0, 0
+	for i, token := range template {
+		if token == templateWildcard {
+			params++
+			continue
+		}
+		if token == tokens[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(template)), params
+}
+
+// mergeTemplate replaces every position where the template and tokens disagree with a wildcard.
+func mergeTemplate(template, tokens []string) bool {
+	changed := false
+	for i, token := range template {
+		if token != templateWildcard && token != tokens[i] {
+			template[i] = templateWildcard
+			changed = true
+		}
+	}
+	return changed
+}
+
+// extractParams returns the raw tokens of content sitting at the template's wildcard positions.
+func extractParams(template []string, content string) []string {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	rawTokens := strings.Fields(firstLine)
+	if len(rawTokens) != len(template) {
+		return nil
+	}
+	var params []string
+	for i, token := range template {
+		if token == templateWildcard {
+			params = append(params, rawTokens[i])
+		}
+	}
+	return params
+}
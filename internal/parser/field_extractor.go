@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Elasticsearch types of extracted fields.
+const (
+	FieldTypeKeyword = "keyword"
+	FieldTypeLong    = "long"
+)
+
+// extractedFieldTypes lists every field the rules below can produce.
+var extractedFieldTypes = map[string]string{
+	"executor_id":    FieldTypeKeyword,
+	"container_id":   FieldTypeKeyword,
+	"stage_id":       FieldTypeLong,
+	"stage_attempt":  FieldTypeLong,
+	"task_id":        FieldTypeLong,
+	"host":           FieldTypeKeyword,
+	"port":           FieldTypeLong,
+	"size_bytes":     FieldTypeLong,
+	"free_bytes":     FieldTypeLong,
+	"capacity_bytes": FieldTypeLong,
+	"duration_ms":    FieldTypeLong,
+}
+
+type FieldExtractor interface {
+	// Extract returns the typed fields found in the first line of content, or nil if there are none.
+	Extract(content string) map[string]any
+	// FieldTypes maps every field Extract can return to its Elasticsearch type.
+	FieldTypes() map[string]string
+}
+
+type fieldConverter func(string) (any, bool)
+
+// fieldRule captures one field per regex group. Rules are tried in order and the first rule
+// to produce a field wins, so more specific rules go first.
+type fieldRule struct {
+	regex    *regexp.Regexp
+	names    []string
+	converts []fieldConverter
+	skip     func(line string, match []int) bool // Optional, rejects matches the regex cannot tell apart
+}
+
+func newFieldRule(pattern string, names []string, converts ...fieldConverter) fieldRule {
+	return fieldRule{regex: regexp.MustCompile(pattern), names: names, converts: converts}
+}
+
+// except makes the rule use the first match that skip does not reject.
+func (r fieldRule) except(skip func(line string, match []int) bool) fieldRule {
+	r.skip = skip
+	return r
+}
+
+// find returns the groups of the first match the rule does not skip, or nil.
+func (r fieldRule) find(line string) []string {
+	if r.skip == nil {
+		return r.regex.FindStringSubmatch(line)
+	}
+	for _, match := range r.regex.FindAllStringSubmatchIndex(line, -1) {
+		if r.skip(line, match) {
+			continue
+		}
+		groups := make([]string, len(match)/2)
+		for i := range groups {
+			if match[2*i] >= 0 {
+				groups[i] = line[match[2*i]:match[2*i+1]]
+			}
+		}
+		return groups
+	}
+	return nil
+}
+
+// sourceExtensions end the file names of stack frames, like Executor.scala:338.
+var sourceExtensions = []string{".java", ".scala", ".kt", ".py", ".go"}
+
+// isStackFrame tells a "(File.scala:338)" stack frame location from a host and port.
+func isStackFrame(line string, match []int) bool {
+	if match[0] > 0 && line[match[0]-1] == '(' || match[1] < len(line) && line[match[1]] == ')' {
+		return true
+	}
+	host := line[match[2]:match[3]]
+	for _, extension := range sourceExtensions {
+		if strings.HasSuffix(host, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+type fieldExtractor struct {
+	rules []fieldRule
+}
+
+func NewFieldExtractor() FieldExtractor {
+	const byteSize = `(\d+(?:\.\d+)? [KMGT]?B)\b`
+	return &fieldExtractor{rules: []fieldRule{
+		// Starting executor ID 3 on host mesos-slave-07 / ... on host-1 (executor 3)
+		newFieldRule(`(?i)\bexecutor(?: ID)? (\d+)\b`, []string{"executor_id"}, asKeyword),
+		newFieldRule(`\b(container_(?:e\d+_)?\d+_\d+_\d+_\d+)\b`, []string{"container_id"}, asKeyword),
+		// Running task 0.0 in stage 1.0 (TID 5)
+		newFieldRule(`\bstage (\d+)\.(\d+)\b`, []string{"stage_id", "stage_attempt"}, asLong, asLong),
+		newFieldRule(`\b(?:TID|assigned task) (\d+)\b`, []string{"task_id"}, asLong),
+		newFieldRule(`\bon host ([\w.\-]+)`, []string{"host"}, asKeyword),
+		newFieldRule(`\bon port (\d+)\b`, []string{"port"}, asLong),
+		// spark://CoarseGrainedScheduler@10.10.34.11:44256, hdfs://10.10.34.11:9000/...
+		newFieldRule(`\b((?:\d{1,3}\.){3}\d{1,3}|[A-Za-z][\w\-]*(?:\.[\w\-]+)*):(\d{2,5})\b`, []string{"host", "port"}, asKeyword, asLong).
+			except(isStackFrame),
+		// Block broadcast_0 stored as values in memory (estimated size 5.1 KB, free 366.3 MB)
+		newFieldRule(`\bestimated size `+byteSize, []string{"size_bytes"}, asByteSize),
+		newFieldRule(`\b(\d+) bytes\b`, []string{"size_bytes"}, asLong),
+		newFieldRule(`\bfree `+byteSize, []string{"free_bytes"}, asByteSize),
+		newFieldRule(`\bcapacity `+byteSize, []string{"capacity_bytes"}, asByteSize),
+		// Reading broadcast variable 3 took 12 ms / took 1.5 s / Times: total = 41, boot = ...
+		newFieldRule(`\b(\d+) ms\b`, []string{"duration_ms"}, asLong),
+		newFieldRule(`\btook (\d+(?:\.\d+)?) s\b`, []string{"duration_ms"}, asSecondsInMillis),
+		newFieldRule(`\bTimes: total = (\d+)\b`, []string{"duration_ms"}, asLong),
+	}}
+}
+
+func (e *fieldExtractor) Extract(content string) map[string]any {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	var fields map[string]any
+	for _, rule := range e.rules {
+		matches := rule.find(firstLine)
+		if matches == nil {
+			continue
+		}
+		for i, name := range rule.names {
+			if _, exists := fields[name]; exists {
+				continue
+			}
+			value, ok := rule.converts[i](matches[i+1])
+			if !ok {
+				continue
+			}
+			if fields == nil {
+				fields = make(map[string]any)
+			}
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+func (e *fieldExtractor) FieldTypes() map[string]string {
+	types := make(map[string]string, len(extractedFieldTypes))
+	for name, typ := range extractedFieldTypes {
+		types[name] = typ
+	}
+	return types
+}
+
+func asKeyword(s string) (any, bool) {
+	return s, s != ""
+}
+
+func asLong(s string) (any, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+func asSecondsInMillis(s string) (any, bool) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, false
+	}
+	return int64(math.Round(seconds * 1000)), true
+}
+
+// byteUnits follow Spark's Utils.bytesToString, which uses binary multiples.
+var byteUnits = map[string]float64{
+	"B":  1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+	"TB": 1 << 40,
+}
+
+// asByteSize converts a human-readable size such as "366.3 MB" to bytes.
+func asByteSize(s string) (any, bool) {
+	number, unit, ok := strings.Cut(s, " ")
+	if !ok {
+		return nil, false
+	}
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return nil, false
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, false
+	}
+	return int64(math.Round(value * multiplier)), true
+}
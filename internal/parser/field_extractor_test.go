@@ -0,0 +1,77 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"skeleton-internship-backend/internal/parser"
+)
+
+func TestFieldExtractor_SparkMessages(t *testing.T) {
+	e := parser.NewFieldExtractor()
+
+	tests := []struct {
+		content string
+		fields  map[string]any
+	}{
+		{
+			content: "Running task 0.0 in stage 1.0 (TID 5)",
+			fields:  map[string]any{"stage_id": int64(1), "stage_attempt": int64(0), "task_id": int64(5)},
+		},
+		{
+			content: "Finished task 2.0 in stage 3.1 (TID 17). 2087 bytes result sent to driver",
+			fields:  map[string]any{"stage_id": int64(3), "stage_attempt": int64(1), "task_id": int64(17), "size_bytes": int64(2087)},
+		},
+		{
+			content: "Block broadcast_0 stored as values in memory (estimated size 5.1 KB, free 366.3 MB)",
+			fields:  map[string]any{"size_bytes": int64(5222), "free_bytes": int64(384093389)},
+		},
+		{
+			content: "Starting executor ID 3 on host mesos-slave-07",
+			fields:  map[string]any{"executor_id": "3", "host": "mesos-slave-07"},
+		},
+		{
+			content: "Connecting to driver: spark://CoarseGrainedScheduler@10.10.34.11:44256",
+			fields:  map[string]any{"host": "10.10.34.11", "port": int64(44256)},
+		},
+		{
+			content: "Reading broadcast variable 4 took 12 ms",
+			fields:  map[string]any{"duration_ms": int64(12)},
+		},
+		{
+			content: "Deleting directory /opt/hdfs/nodemanager/usercache/curi/appcache/container_1485248649253_0186_02_000017",
+			fields:  map[string]any{"container_id": "container_1485248649253_0186_02_000017"},
+		},
+		{
+			content: "Shutdown hook called",
+			fields:  nil,
+		},
+		{
+			// Stack frames are not host:port
+			content: "\tat org.apache.spark.executor.Executor$TaskRunner.run(Executor.scala:338)",
+			fields:  nil,
+		},
+		{
+			content: "Lost task 1.0 in stage 2.0 (TID 7) at Executor.scala:338, fetching from 10.10.34.11:7337",
+			fields:  map[string]any{"stage_id": int64(2), "stage_attempt": int64(0), "task_id": int64(7), "host": "10.10.34.11", "port": int64(7337)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			assert.Equal(t, tt.fields, e.Extract(tt.content))
+		})
+	}
+}
+
+func TestFieldExtractor_FieldTypesCoverExtractedFields(t *testing.T) {
+	e := parser.NewFieldExtractor()
+	types := e.FieldTypes()
+
+	fields := e.Extract("Finished task 0.0 in stage 0.0 (TID 0) in 1234 ms on host mesos-slave-07 (executor 2)")
+	assert.NotEmpty(t, fields)
+	for name := range fields {
+		assert.Contains(t, types, name)
+	}
+}
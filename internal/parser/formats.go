@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	FormatAuto   = "auto"
+	FormatSpark  = "spark"  // 17/01/24 10:15:30 INFO storage.BlockManager: msg
+	FormatYarn   = "yarn"   // 2017-01-24 10:15:30,123 INFO org.apache.hadoop.yarn...NodeManager: msg
+	FormatLog4j2 = "log4j2" // 2017-01-24T10:15:30.123+07:00 INFO [main] com.example.Foo - msg
+	FormatJSON   = "json"   // {"timestamp":"...","level":"INFO","logger":"...","message":"..."}
+)
+
+type yarnParser struct {
+	// Groups: 1:DateTime, 2:Level, 3:Component, 4:InitialContent
+	headerRegex *regexp.Regexp
+}
+
+func NewYarnParser() LogParser {
+	regex := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2},\d{3})\s+(\w+)\s+([\w\.\-\$]+)\s*:\s*(.*)$`)
+	return &yarnParser{headerRegex: regex}
+}
+
+func (p *yarnParser) Name() string {
+	return FormatYarn
+}
+
+func (p *yarnParser) ParseHeader(line string) (*HeaderInfo, bool) {
+	matches := p.headerRegex.FindStringSubmatch(line)
+	if len(matches) != 5 {
+		return nil, false
+	}
+
+	const layout = "2006-01-02 15:04:05,000"
+	timestamp, err := time.Parse(layout, matches[1])
+	if err != nil {
+		log.Warn().Err(err).Str("datetime_string", matches[1]).Str("line", line).Msg("Failed to parse timestamp in header, will be inferred")
+	}
+
+	return &HeaderInfo{
+		Timestamp:      timestamp.UTC(),
+		Level:          strings.ToUpper(matches[2]),
+		Component:      matches[3],
+		InitialContent: strings.TrimSpace(matches[4]),
+		Unparsed:       err != nil,
+	}, true
+}
+
+type log4j2Parser struct {
+	// Groups: 1:DateTime, 2:Level, 3:Thread (optional), 4:Component, 5:InitialContent
+	headerRegex *regexp.Regexp
+}
+
+func NewLog4j2Parser() LogParser {
+	regex := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+\-]\d{2}:?\d{2})?)\s+(\w+)\s+(?:\[([^\]]*)\]\s+)?([\w\.\-\$]+)\s*[-:]\s*(.*)$`)
+	return &log4j2Parser{headerRegex: regex}
+}
+
+func (p *log4j2Parser) Name() string {
+	return FormatLog4j2
+}
+
+func (p *log4j2Parser) ParseHeader(line string) (*HeaderInfo, bool) {
+	matches := p.headerRegex.FindStringSubmatch(line)
+	if len(matches) != 6 {
+		return nil, false
+	}
+
+	timestamp, zoned, err := parseISOTimestamp(matches[1])
+	if err != nil {
+		log.Warn().Err(err).Str("datetime_string", matches[1]).Str("line", line).Msg("Failed to parse timestamp in header, will be inferred")
+	}
+
+	return &HeaderInfo{
+		Timestamp:      timestamp.UTC(),
+		Level:          strings.ToUpper(matches[2]),
+		Component:      matches[4],
+		InitialContent: strings.TrimSpace(matches[5]),
+		Zoned:          zoned,
+		Unparsed:       err != nil,
+	}, true
+}
+
+// parseISOTimestamp accepts ISO-8601 timestamps with or without an offset and reports whether one was present.
+func parseISOTimestamp(value string) (time.Time, bool, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	zonedLayouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999Z0700",
+	}
+	var err error
+	for _, layout := range zonedLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, true, nil
+		}
+	}
+	t, err := time.Parse("2006-01-02T15:04:05.999999999", value)
+	return t, false, err
+}
+
+type jsonLinesParser struct{}
+
+func NewJSONLinesParser() LogParser {
+	return &jsonLinesParser{}
+}
+
+func (p *jsonLinesParser) Name() string {
+	return FormatJSON
+}
+
+// Field names tried in order for each header attribute.
+var (
+	jsonTimestampKeys = []string{"@timestamp", "timestamp", "time", "ts"}
+	jsonLevelKeys     = []string{"level", "severity", "log.level", "lvl"}
+	jsonComponentKeys = []string{"logger", "logger_name", "component", "class", "source"}
+	jsonMessageKeys   = []string{"message", "msg", "content", "log"}
+)
+
+func (p *jsonLinesParser) ParseHeader(line string) (*HeaderInfo, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil {
+		return nil, false
+	}
+
+	info := &HeaderInfo{
+		Level:     strings.ToUpper(firstStringField(fields, jsonLevelKeys)),
+		Component: firstStringField(fields, jsonComponentKeys),
+	}
+	if info.Level == "" {
+		info.Level = "UNKNOWN"
+	}
+	if info.Component == "" {
+		info.Component = "UNKNOWN"
+	}
+	info.InitialContent = firstStringField(fields, jsonMessageKeys)
+	if info.InitialContent == "" {
+		info.InitialContent = trimmed
+	}
+
+	info.Unparsed = true
+	if ts, ok := firstField(fields, jsonTimestampKeys); ok {
+		switch v := ts.(type) {
+		case string:
+			if t, zoned, err := parseISOTimestamp(v); err == nil {
+				info.Timestamp, info.Zoned, info.Unparsed = t.UTC(), zoned, false
+			} else {
+				log.Warn().Err(err).Str("datetime_string", v).Msg("Failed to parse JSON log timestamp, will be inferred")
+			}
+		case float64:
+			// Epoch seconds or milliseconds
+			if v > 1e12 {
+				info.Timestamp = time.UnixMilli(int64(v)).UTC()
+			} else {
+				info.Timestamp = time.Unix(0, int64(v*float64(time.Second))).UTC()
+			}
+			info.Zoned, info.Unparsed = true, false
+		}
+	}
+	return info, true
+}
+
+func firstField(fields map[string]interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok && v != nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func firstStringField(fields map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
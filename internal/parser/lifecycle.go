@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"skeleton-internship-backend/internal/model"
+)
+
+var (
+	// Groups: 1:Attempt. The optional e<epoch>_ part appears after a ResourceManager restart.
+	containerIDRegex = regexp.MustCompile(`^container_(?:e\d+_)?\d+_\d+_(\d+)_\d+$`)
+
+	attemptStartedRegex     = regexp.MustCompile(`ApplicationAttemptId: (appattempt_\d+_\d+_(\d+))`)
+	finalStatusRegex        = regexp.MustCompile(`Final app status: (\w+), exitCode: (-?\d+)(?:, \(reason: (.*)\))?`)
+	unregisterRegex         = regexp.MustCompile(`Unregistering ApplicationMaster with (\w+)(?: \(diag message: (.*)\))?`)
+	containerLaunchedRegex  = regexp.MustCompile(`Launching container (container_\w+)\b.*? on host ([\w.\-]+)`)
+	containerCompletedRegex = regexp.MustCompile(`Completed container (container_\w+)(?: on host: ([\w.\-]+))? \(state: \w+, exit status: (-?\d+)\)`)
+	containerFailedRegex    = regexp.MustCompile(`Container marked as failed: (container_\w+) on host: ([\w.\-]+)\. Exit status: (-?\d+)\.(?: Diagnostics: (.*))?`)
+)
+
+// YARN exit statuses of containers that were stopped rather than crashing:
+// 137/143 are SIGKILL/SIGTERM, negative values are ContainerExitStatus constants (preempted, over memory limits...).
+var killedExitStatuses = map[int]bool{137: true, 143: true, -100: true, -102: true, -103: true, -104: true, -105: true, -106: true}
+
+// ExtractContainerID returns the YARN container ID from a container log path such as
+// .../application_1485248649253_0186/container_1485248649253_0186_02_000017.log.
+func ExtractContainerID(filePath string) (string, bool) {
+	base := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	if !containerIDRegex.MatchString(base) {
+		return "", false
+	}
+	return base, true
+}
+
+// ContainerAttempt returns the application attempt encoded in a container ID, or 0 if it is not one.
+func ContainerAttempt(containerID string) int {
+	matches := containerIDRegex.FindStringSubmatch(containerID)
+	if matches == nil {
+		return 0
+	}
+	attempt, _ := strconv.Atoi(matches[1])
+	return attempt
+}
+
+// ParseApplicationEvent extracts lifecycle facts from ApplicationMaster and YarnAllocator messages.
+func ParseApplicationEvent(entry *model.LogEntry) (model.ApplicationEvent, bool) {
+	if !strings.Contains(entry.Component, "ApplicationMaster") && !strings.Contains(entry.Component, "YarnAllocator") {
+		return model.ApplicationEvent{}, false
+	}
+	firstLine, _, _ := strings.Cut(entry.Content, "\n")
+	event := model.ApplicationEvent{
+		Application: entry.Application,
+		Time:        entry.Timestamp,
+		Attempt:     entry.Attempt,
+	}
+
+	if matches := attemptStartedRegex.FindStringSubmatch(firstLine); matches != nil {
+		event.Kind = model.AppEventAttemptStarted
+		event.AttemptID = matches[1]
+		event.Attempt, _ = strconv.Atoi(matches[2])
+		return event, true
+	}
+	if matches := finalStatusRegex.FindStringSubmatch(firstLine); matches != nil {
+		event.Kind = model.AppEventFinished
+		event.Status = strings.ToUpper(matches[1])
+		event.ExitCode = parseExitCode(matches[2])
+		event.Diagnostics = matches[3]
+		return event, true
+	}
+	if matches := unregisterRegex.FindStringSubmatch(firstLine); matches != nil {
+		event.Kind = model.AppEventFinished
+		event.Status = strings.ToUpper(matches[1])
+		event.Diagnostics = matches[2]
+		return event, true
+	}
+	if matches := containerLaunchedRegex.FindStringSubmatch(firstLine); matches != nil {
+		event.Kind = model.AppEventContainerLaunched
+		event.ContainerID = matches[1]
+		event.Attempt = ContainerAttempt(matches[1])
+		event.Host = matches[2]
+		return event, true
+	}
+	if matches := containerCompletedRegex.FindStringSubmatch(firstLine); matches != nil {
+		event.Kind = model.AppEventContainerCompleted
+		event.ContainerID = matches[1]
+		event.Attempt = ContainerAttempt(matches[1])
+		event.Host = matches[2]
+		event.ExitCode = parseExitCode(matches[3])
+		event.Status = containerState(*event.ExitCode)
+		return event, true
+	}
+	if matches := containerFailedRegex.FindStringSubmatch(firstLine); matches != nil {
+		event.Kind = model.AppEventContainerCompleted
+		event.ContainerID = matches[1]
+		event.Attempt = ContainerAttempt(matches[1])
+		event.Host = matches[2]
+		event.ExitCode = parseExitCode(matches[3])
+		event.Status = containerState(*event.ExitCode)
+		event.Diagnostics = strings.TrimSpace(matches[4])
+		return event, true
+	}
+	return model.ApplicationEvent{}, false
+}
+
+func parseExitCode(s string) *int {
+	code, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &code
+}
+
+func containerState(exitStatus int) string {
+	switch {
+	case exitStatus == 0:
+		return model.ContainerStateCompleted
+	case killedExitStatuses[exitStatus]:
+		return model.ContainerStateKilled
+	default:
+		return model.ContainerStateFailed
+	}
+}
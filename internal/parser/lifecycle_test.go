@@ -0,0 +1,79 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+)
+
+const amLogPath = "/logs/application_1485248649253_0186/container_1485248649253_0186_02_000001.log"
+
+func TestExtractPathMetadata(t *testing.T) {
+	meta := parser.ExtractPathMetadata(amLogPath)
+	assert.Equal(t, parser.PathMetadata{
+		Application: "application_1485248649253_0186",
+		ContainerID: "container_1485248649253_0186_02_000001",
+		Attempt:     2,
+	}, meta)
+
+	meta = parser.ExtractPathMetadata("/logs/application_1/container_e17_1485248649253_0186_01_000003.log")
+	assert.Equal(t, "container_e17_1485248649253_0186_01_000003", meta.ContainerID)
+	assert.Equal(t, 1, meta.Attempt)
+
+	meta = parser.ExtractPathMetadata("/var/log/userlogs/application_1485248649253_0186/container_1485248649253_0186_02_000017/stderr")
+	assert.Equal(t, parser.PathMetadata{
+		Application: "application_1485248649253_0186",
+		ContainerID: "container_1485248649253_0186_02_000017",
+		Attempt:     2,
+	}, meta)
+
+	meta = parser.ExtractPathMetadata("/logs/nodemanager/yarn.log")
+	assert.Equal(t, parser.PathMetadata{Application: "unknown_application"}, meta)
+}
+
+func TestParseApplicationEvent(t *testing.T) {
+	ts := time.Date(2017, 7, 27, 14, 35, 58, 0, time.UTC)
+	newEntry := func(component, content string) *model.LogEntry {
+		return &model.LogEntry{
+			Timestamp:   ts,
+			Component:   component,
+			Content:     content,
+			Application: "application_1485248649253_0186",
+			ContainerID: "container_1485248649253_0186_02_000001",
+			Attempt:     2,
+			SourceFile:  amLogPath,
+		}
+	}
+
+	event, ok := parser.ParseApplicationEvent(newEntry("yarn.ApplicationMaster", "ApplicationAttemptId: appattempt_1485248649253_0186_000002"))
+	require.True(t, ok)
+	assert.Equal(t, model.AppEventAttemptStarted, event.Kind)
+	assert.Equal(t, 2, event.Attempt)
+	assert.Equal(t, ts, event.Time)
+
+	event, ok = parser.ParseApplicationEvent(newEntry("yarn.ApplicationMaster", "Final app status: FAILED, exitCode: 15, (reason: User class threw exception: java.lang.OutOfMemoryError)"))
+	require.True(t, ok)
+	assert.Equal(t, model.AppEventFinished, event.Kind)
+	assert.Equal(t, model.AppStatusFailed, event.Status)
+	require.NotNil(t, event.ExitCode)
+	assert.Equal(t, 15, *event.ExitCode)
+	assert.Equal(t, "User class threw exception: java.lang.OutOfMemoryError", event.Diagnostics)
+
+	event, ok = parser.ParseApplicationEvent(newEntry("yarn.YarnAllocator", "Launching container container_1485248649253_0186_02_000003 on host mesos-slave-07"))
+	require.True(t, ok)
+	assert.Equal(t, model.AppEventContainerLaunched, event.Kind)
+	assert.Equal(t, "mesos-slave-07", event.Host)
+
+	event, ok = parser.ParseApplicationEvent(newEntry("yarn.YarnAllocator", "Completed container container_1485248649253_0186_02_000003 on host: mesos-slave-07 (state: COMPLETE, exit status: 143)"))
+	require.True(t, ok)
+	assert.Equal(t, model.AppEventContainerCompleted, event.Kind)
+	assert.Equal(t, model.ContainerStateKilled, event.Status)
+
+	_, ok = parser.ParseApplicationEvent(newEntry("executor.Executor", "Launching container container_1485248649253_0186_02_000003 on host mesos-slave-07"))
+	assert.False(t, ok)
+}
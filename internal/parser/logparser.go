@@ -10,14 +10,21 @@ import (
 )
 
 type HeaderInfo struct {
+	// Timestamp as written in the line. Unless Zoned is set it is a wall-clock time stored as UTC,
+	// and TimestampResolver moves it into the source's configured location.
 	Timestamp      time.Time
 	Level          string
 	Component      string
 	InitialContent string
+
+	Zoned       bool // The line carried its own UTC offset
+	YearMissing bool // The format has no year (e.g. "Jan  2 15:04:05"), so it must be inferred
+	Unparsed    bool // The timestamp could not be parsed, Timestamp is zero
 }
 
 type LogParser interface {
 	ParseHeader(line string) (*HeaderInfo, bool)
+	Name() string
 }
 
 type multilineCapableParser struct {
@@ -30,6 +37,10 @@ func NewMultilineCapableParser() LogParser {
 	return &multilineCapableParser{headerRegex: regex}
 }
 
+func (p *multilineCapableParser) Name() string {
+	return FormatSpark
+}
+
 func (p *multilineCapableParser) ParseHeader(line string) (*HeaderInfo, bool) {
 	matches := p.headerRegex.FindStringSubmatch(line)
 
@@ -46,8 +57,7 @@ func (p *multilineCapableParser) ParseHeader(line string) (*HeaderInfo, bool) {
 	const layout = "06/01/02 15:04:05"
 	timestamp, err := time.Parse(layout, dateStr+" "+timeStr)
 	if err != nil {
-		log.Warn().Err(err).Str("datetime_string", dateStr+" "+timeStr).Str("line", line).Msg("Failed to parse timestamp in header, using current time")
-		timestamp = time.Now().UTC()
+		log.Warn().Err(err).Str("datetime_string", dateStr+" "+timeStr).Str("line", line).Msg("Failed to parse timestamp in header, will be inferred")
 	}
 
 	info := &HeaderInfo{
@@ -55,19 +65,39 @@ func (p *multilineCapableParser) ParseHeader(line string) (*HeaderInfo, bool) {
 		Level:          strings.ToUpper(level),
 		Component:      component,
 		InitialContent: initialContent,
+		Unparsed:       err != nil,
 	}
 
 	return info, true
 }
 
-func ExtractApplicationID(filePath string) string {
-	// Get the directory containing the file
+// PathMetadata is what a log file's location tells about the lines in it.
+type PathMetadata struct {
+	Application string
+	ContainerID string // Empty for files that are not container logs
+	Attempt     int    // Application attempt of the container, 0 if unknown
+}
+
+// ExtractPathMetadata reads the application from the parent directory and the container from the
+// file name, e.g. .../application_1485248649253_0186/container_1485248649253_0186_02_000017.log.
+// In YARN's own layout the container is a directory holding stdout, stderr and syslog, e.g.
+// .../userlogs/application_1485248649253_0186/container_1485248649253_0186_02_000017/stderr.
+func ExtractPathMetadata(filePath string) PathMetadata {
+	meta := PathMetadata{Application: "unknown_application"}
 	dir := filepath.Dir(filePath)
+	containerID, ok := ExtractContainerID(filePath)
+	if !ok && containerIDRegex.MatchString(filepath.Base(dir)) {
+		containerID, ok = filepath.Base(dir), true
+		dir = filepath.Dir(dir)
+	}
+	if ok {
+		meta.ContainerID = containerID
+		meta.Attempt = ContainerAttempt(containerID)
+	}
 	// Get the base name of the directory (e.g., application_12345_0001)
 	baseDir := filepath.Base(dir)
 	if strings.HasPrefix(baseDir, "application_") {
-		return baseDir
+		meta.Application = baseDir
 	}
-	// Fallback if the structure is different
-	return "unknown_application"
+	return meta
 }
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"skeleton-internship-backend/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// detectLines is how many non-empty lines from the start of a file are sampled for auto-detection.
+const detectLines = 20
+
+// Registry holds the available LogParser implementations and picks one per file,
+// either from a directory rule in config or by sampling the head of the file.
+type Registry struct {
+	parsers       map[string]LogParser
+	order         []string // Detection order, earlier wins on ties
+	defaultFormat string
+	rules         dirRules
+
+	mu       sync.RWMutex
+	detected map[string]LogParser // File path -> detected parser
+}
+
+// dirRules maps directory globs to a value. A glob matches either the directory's base name
+// or its full path; longer patterns are usually more specific and are checked first.
+type dirRules []dirRule
+
+type dirRule struct {
+	pattern string
+	value   string
+}
+
+func newDirRules(raw map[string]string) (dirRules, error) {
+	rules := make(dirRules, 0, len(raw))
+	for pattern, value := range raw {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid directory pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, dirRule{pattern: pattern, value: value})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].pattern) != len(rules[j].pattern) {
+			return len(rules[i].pattern) > len(rules[j].pattern)
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+	return rules, nil
+}
+
+func (rules dirRules) lookup(dir string) (string, bool) {
+	base := filepath.Base(dir)
+	for _, rule := range rules {
+		if ok, _ := filepath.Match(rule.pattern, base); ok {
+			return rule.value, true
+		}
+		if ok, _ := filepath.Match(rule.pattern, dir); ok {
+			return rule.value, true
+		}
+	}
+	return "", false
+}
+
+func NewParserRegistry(cfg *config.Config) (*Registry, error) {
+	r := &Registry{
+		parsers:       make(map[string]LogParser),
+		defaultFormat: strings.ToLower(cfg.LogProcessor.DefaultFormat),
+		detected:      make(map[string]LogParser),
+	}
+	for _, p := range []LogParser{NewMultilineCapableParser(), NewYarnParser(), NewLog4j2Parser(), NewJSONLinesParser()} {
+		r.Register(p)
+	}
+
+	if r.defaultFormat == "" {
+		r.defaultFormat = FormatAuto
+	}
+	if err := r.validateFormat(r.defaultFormat); err != nil {
+		return nil, err
+	}
+	formatRules := make(map[string]string, len(cfg.LogProcessor.FormatRules))
+	for pattern, format := range cfg.LogProcessor.FormatRules {
+		format = strings.ToLower(format)
+		if err := r.validateFormat(format); err != nil {
+			return nil, err
+		}
+		formatRules[pattern] = format
+	}
+	rules, err := newDirRules(formatRules)
+	if err != nil {
+		return nil, err
+	}
+	r.rules = rules
+
+	log.Info().Strs("formats", r.order).Str("default", r.defaultFormat).Int("rules", len(r.rules)).Msg("Log parser registry initialized")
+	return r, nil
+}
+
+// Register adds a parser, replacing any parser already registered under the same name.
+func (r *Registry) Register(p LogParser) {
+	if _, exists := r.parsers[p.Name()]; !exists {
+		r.order = append(r.order, p.Name())
+	}
+	r.parsers[p.Name()] = p
+}
+
+// Get returns the parser registered under name.
+func (r *Registry) Get(name string) (LogParser, bool) {
+	p, ok := r.parsers[strings.ToLower(name)]
+	return p, ok
+}
+
+func (r *Registry) validateFormat(format string) error {
+	if format == FormatAuto {
+		return nil
+	}
+	if _, ok := r.parsers[format]; !ok {
+		return fmt.Errorf("unknown log format %q (available: %s, %s)", format, FormatAuto, strings.Join(r.order, ", "))
+	}
+	return nil
+}
+
+// ForFile returns the parser for filePath: the first matching directory rule, else the default
+// format, auto-detecting from the file contents when that resolves to "auto".
+func (r *Registry) ForFile(filePath string) LogParser {
+	format := r.formatForDir(filepath.Dir(filePath))
+	if format != FormatAuto {
+		return r.parsers[format]
+	}
+
+	r.mu.RLock()
+	p, ok := r.detected[filePath]
+	r.mu.RUnlock()
+	if ok {
+		return p
+	}
+
+	lines, err := readHeadLines(filePath, detectLines)
+	if err != nil {
+		log.Warn().Err(err).Str("file", filePath).Msg("Failed to sample file for format detection, using spark format")
+		return r.parsers[FormatSpark]
+	}
+	p, ok = r.Detect(lines)
+	if !ok {
+		// Nothing recognizable yet (e.g. empty file); do not cache so we retry next cycle
+		return r.parsers[FormatSpark]
+	}
+	r.mu.Lock()
+	r.detected[filePath] = p
+	r.mu.Unlock()
+	log.Info().Str("file", filePath).Str("format", p.Name()).Msg("Detected log format")
+	return p
+}
+
+// Forget drops the formats detected for filePaths, for files whose state was dropped.
+func (r *Registry) Forget(filePaths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, filePath := range filePaths {
+		delete(r.detected, filePath)
+	}
+}
+
+// ForStream returns the parser for lines that cannot be sampled from a file on disk, such as a
+// decompressed stream or an HTTP upload: the directory rule or default format for path, else the
+// format detected from sample.
+func (r *Registry) ForStream(path string, sample []string) LogParser {
+	format := r.formatForDir(filepath.Dir(path))
+	if format != FormatAuto {
+		return r.parsers[format]
+	}
+	if len(sample) > detectLines {
+		sample = sample[:detectLines]
+	}
+	if p, ok := r.Detect(sample); ok {
+		return p
+	}
+	return r.parsers[FormatSpark]
+}
+
+// Detect picks the parser recognizing the most header lines in the sample.
+func (r *Registry) Detect(lines []string) (LogParser, bool) {
+	var best LogParser
+	bestHits := 0
+	for _, name := range r.order {
+		p := r.parsers[name]
+		hits := 0
+		for _, line := range lines {
+			if _, ok := p.ParseHeader(line); ok {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = p, hits
+		}
+	}
+	return best, best != nil
+}
+
+func (r *Registry) formatForDir(dir string) string {
+	if format, ok := r.rules.lookup(dir); ok {
+		return format
+	}
+	return r.defaultFormat
+}
+
+func readHeadLines(filePath string, n int) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(file)
+	for len(lines) < n && scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
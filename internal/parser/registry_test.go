@@ -0,0 +1,127 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/parser"
+)
+
+func newTestRegistry(t *testing.T, defaultFormat string, rules map[string]string) *parser.Registry {
+	t.Helper()
+	r, err := parser.NewParserRegistry(&config.Config{
+		LogProcessor: config.LogProcessorConfig{DefaultFormat: defaultFormat, FormatRules: rules},
+	})
+	require.NoError(t, err)
+	return r
+}
+
+func TestRegistry_ParseHeaderPerFormat(t *testing.T) {
+	r := newTestRegistry(t, "", nil)
+
+	tests := []struct {
+		format    string
+		line      string
+		timestamp time.Time
+		level     string
+		component string
+		content   string
+	}{
+		{
+			format:    parser.FormatSpark,
+			line:      "17/07/27 21:35:58 INFO executor.CoarseGrainedExecutorBackend: Registered signal handlers",
+			timestamp: time.Date(2017, 7, 27, 21, 35, 58, 0, time.UTC),
+			level:     "INFO",
+			component: "executor.CoarseGrainedExecutorBackend",
+			content:   "Registered signal handlers",
+		},
+		{
+			format:    parser.FormatYarn,
+			line:      "2017-01-24 10:15:30,123 WARN org.apache.hadoop.yarn.server.nodemanager.NodeManager: Container killed",
+			timestamp: time.Date(2017, 1, 24, 10, 15, 30, 123e6, time.UTC),
+			level:     "WARN",
+			component: "org.apache.hadoop.yarn.server.nodemanager.NodeManager",
+			content:   "Container killed",
+		},
+		{
+			format:    parser.FormatLog4j2,
+			line:      "2024-05-01T12:34:56.789+07:00 error [main] com.example.Worker - Task failed",
+			timestamp: time.Date(2024, 5, 1, 5, 34, 56, 789e6, time.UTC),
+			level:     "ERROR",
+			component: "com.example.Worker",
+			content:   "Task failed",
+		},
+		{
+			format:    parser.FormatJSON,
+			line:      `{"@timestamp":"2024-05-01T12:34:56Z","level":"info","logger":"api.Handler","message":"request served"}`,
+			timestamp: time.Date(2024, 5, 1, 12, 34, 56, 0, time.UTC),
+			level:     "INFO",
+			component: "api.Handler",
+			content:   "request served",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p, ok := r.Get(tt.format)
+			require.True(t, ok)
+
+			header, ok := p.ParseHeader(tt.line)
+			require.True(t, ok)
+			assert.True(t, tt.timestamp.Equal(header.Timestamp), "timestamp %s", header.Timestamp)
+			assert.Equal(t, tt.level, header.Level)
+			assert.Equal(t, tt.component, header.Component)
+			assert.Equal(t, tt.content, header.InitialContent)
+
+			detected, ok := r.Detect([]string{tt.line, "\tat some.stack.Frame(Frame.java:1)"})
+			require.True(t, ok)
+			assert.Equal(t, tt.format, detected.Name())
+		})
+	}
+}
+
+func TestRegistry_ForFile(t *testing.T) {
+	root := t.TempDir()
+	write := func(dir, name, content string) string {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+		path := filepath.Join(root, dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	yarnFile := write("application_1_0001", "container.log", "2017-01-24 10:15:30,123 INFO org.apache.Foo: hello\n")
+	jsonFile := write("nodemanager-01", "nm.log", "2017-01-24 10:15:30,123 INFO org.apache.Foo: hello\n")
+	emptyFile := write("application_1_0002", "container.log", "")
+
+	r := newTestRegistry(t, parser.FormatAuto, map[string]string{"nodemanager*": parser.FormatJSON})
+
+	assert.Equal(t, parser.FormatYarn, r.ForFile(yarnFile).Name())
+	assert.Equal(t, parser.FormatJSON, r.ForFile(jsonFile).Name(), "directory rule wins over detection")
+	assert.Equal(t, parser.FormatSpark, r.ForFile(emptyFile).Name(), "falls back to spark when nothing is detected")
+}
+
+func TestRegistry_ForgetRedetects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("2017-01-24 10:15:30,123 INFO org.apache.Foo: hello\n"), 0644))
+	r := newTestRegistry(t, parser.FormatAuto, nil)
+	require.Equal(t, parser.FormatYarn, r.ForFile(path).Name())
+
+	// A new file at the same path keeps the cached format until the old one is forgotten
+	require.NoError(t, os.WriteFile(path, []byte(`{"@timestamp":"2024-05-01T12:34:56Z","level":"info","message":"hello"}`+"\n"), 0644))
+	assert.Equal(t, parser.FormatYarn, r.ForFile(path).Name())
+	r.Forget(path)
+	assert.Equal(t, parser.FormatJSON, r.ForFile(path).Name())
+}
+
+func TestRegistry_RejectsUnknownFormat(t *testing.T) {
+	_, err := parser.NewParserRegistry(&config.Config{
+		LogProcessor: config.LogProcessorConfig{DefaultFormat: "syslog"},
+	})
+	assert.Error(t, err)
+}
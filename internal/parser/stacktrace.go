@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"skeleton-internship-backend/internal/model"
+)
+
+// maxFingerprintFrames bounds how many frames of each exception feed the fingerprint, so that
+// traces differing only deep in the call stack (e.g. thread pool internals) still group together.
+const maxFingerprintFrames = 10
+
+var (
+	// The first exception may follow a prefix such as `Exception in thread "main" ` or `Lost task 0.0 ...: `
+	firstExceptionRegex = regexp.MustCompile(`(?:^|[\s:("])((?:[A-Za-z_$][\w$]*\.)+[\w$]*(?:Exception|Error|Throwable))(?::\s*(.*))?$`)
+	causedByRegex       = regexp.MustCompile(`^Caused by:\s*((?:[A-Za-z_$][\w$]*\.)*[A-Za-z_$][\w$]*)(?::\s*(.*))?$`)
+	// Groups: 1:Method, 2:Location ("Executor.scala:338", "Native Method", "Unknown Source")
+	frameRegex    = regexp.MustCompile(`^\s+at\s+(\S+)\(([^)]*)\)`)
+	moreRegex     = regexp.MustCompile(`^\s+\.\.\. (\d+) more`)
+	suppressedTag = "Suppressed:"
+
+	// Parts of frames that vary between JVMs or runs of the same code
+	hexAddressRegex     = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	lambdaRegex         = regexp.MustCompile(`\$\$Lambda\$\d+/[\w.$]*`)
+	generatedClassRegex = regexp.MustCompile(`(Generated(?:Serialization)?(?:Method|Constructor)Accessor)\d+`)
+)
+
+// ParseStackTrace extracts the Java stack trace glued into content by the multiline reader.
+// It only reports a trace when at least one "at ..." frame is present.
+func ParseStackTrace(content string) (*model.StackTrace, bool) {
+	var exceptions []model.ExceptionInfo
+	var current *model.ExceptionInfo
+	frameCount := 0
+	suppressed := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if matches := causedByRegex.FindStringSubmatch(line); matches != nil && current != nil {
+			exceptions = append(exceptions, model.ExceptionInfo{Class: matches[1], Message: strings.TrimSpace(matches[2])})
+			current = &exceptions[len(exceptions)-1]
+			suppressed = false
+			continue
+		}
+		if suppressed {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), suppressedTag) {
+			// Suppressed exceptions are not part of the cause chain
+			suppressed = true
+			continue
+		}
+
+		if current == nil {
+			if matches := firstExceptionRegex.FindStringSubmatch(line); matches != nil {
+				exceptions = append(exceptions, model.ExceptionInfo{Class: matches[1], Message: strings.TrimSpace(matches[2])})
+				current = &exceptions[len(exceptions)-1]
+			}
+			continue
+		}
+
+		if matches := frameRegex.FindStringSubmatch(line); matches != nil {
+			current.Frames = append(current.Frames, parseStackFrame(matches[1], matches[2]))
+			frameCount++
+			continue
+		}
+		if matches := moreRegex.FindStringSubmatch(line); matches != nil {
+			current.OmittedFrames, _ = strconv.Atoi(matches[1])
+			continue
+		}
+		if len(current.Frames) == 0 && strings.TrimSpace(line) != "" {
+			// Multi-line exception message
+			current.Message = strings.TrimSpace(current.Message + "\n" + line)
+		}
+	}
+
+	if frameCount == 0 {
+		return nil, false
+	}
+	trace := &model.StackTrace{Exceptions: exceptions}
+	trace.Fingerprint = stackTraceFingerprint(trace)
+	return trace, true
+}
+
+func parseStackFrame(method, location string) model.StackFrame {
+	frame := model.StackFrame{Method: method}
+	file, line, ok := strings.Cut(location, ":")
+	if ok {
+		frame.Line, _ = strconv.Atoi(line)
+	}
+	if file != "Native Method" && file != "Unknown Source" {
+		frame.File = file
+	}
+	return frame
+}
+
+// stackTraceFingerprint hashes the exception classes and their top frames. Messages, line
+// numbers and generated class names are left out so the same failure on different executors,
+// hosts or attempts produces the same fingerprint.
+func stackTraceFingerprint(trace *model.StackTrace) string {
+	var b strings.Builder
+	for _, exception := range trace.Exceptions {
+		b.WriteString(exception.Class)
+		b.WriteByte('\n')
+		for i, frame := range exception.Frames {
+			if i == maxFingerprintFrames {
+				break
+			}
+			b.WriteString(normalizeFrameMethod(frame.Method))
+			b.WriteByte('(')
+			b.WriteString(frame.File)
+			b.WriteString(")\n")
+		}
+	}
+	return fingerprint(b.String())
+}
+
+func normalizeFrameMethod(method string) string {
+	method = lambdaRegex.ReplaceAllString(method, "$$$$Lambda")
+	method = generatedClassRegex.ReplaceAllString(method, "$1")
+	return hexAddressRegex.ReplaceAllString(method, "")
+}
+
+// MessageFingerprint groups error messages without a stack trace by their first line,
+// with every token containing a digit (IDs, hosts, sizes...) masked.
+func MessageFingerprint(content string) string {
+	return fingerprint(strings.Join(tokenize(content), " "))
+}
+
+func fingerprint(normalized string) string {
+	sum := sha1.Sum([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
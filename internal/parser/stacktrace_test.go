@@ -0,0 +1,82 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/parser"
+)
+
+const containerLaunchTrace = `Opening proxy : mesos-slave-09:51130
+Exception in thread "ContainerLauncher-0" java.lang.Error: org.apache.spark.SparkException: Exception while starting container container_1485248649253_0186_02_000003 on host mesos-slave-09
+	at java.util.concurrent.ThreadPoolExecutor.runWorker(ThreadPoolExecutor.java:1151)
+	at java.lang.Thread.run(Thread.java:745)
+Caused by: org.apache.spark.SparkException: Exception while starting container container_1485248649253_0186_02_000003 on host mesos-slave-09
+	at org.apache.spark.deploy.yarn.ExecutorRunnable.startContainer(ExecutorRunnable.scala:125)
+	at org.apache.spark.deploy.yarn.ExecutorRunnable.run(ExecutorRunnable.scala:68)
+	... 2 more
+Caused by: org.apache.hadoop.yarn.exceptions.YarnException: Unauthorized request to start container.
+This token is expired. current time is 1501162708621 found 1501162567859
+	at sun.reflect.GeneratedConstructorAccessor43.newInstance(Unknown Source)
+	at org.apache.hadoop.yarn.api.records.impl.pb.SerializedExceptionPBImpl.instantiateException(SerializedExceptionPBImpl.java:168)`
+
+func TestParseStackTrace_CauseChain(t *testing.T) {
+	trace, ok := parser.ParseStackTrace(containerLaunchTrace)
+	require.True(t, ok)
+	require.Len(t, trace.Exceptions, 3)
+
+	outer := trace.Exceptions[0]
+	assert.Equal(t, "java.lang.Error", outer.Class)
+	assert.Contains(t, outer.Message, "org.apache.spark.SparkException")
+	require.Len(t, outer.Frames, 2)
+	assert.Equal(t, "java.util.concurrent.ThreadPoolExecutor.runWorker", outer.Frames[0].Method)
+	assert.Equal(t, "ThreadPoolExecutor.java", outer.Frames[0].File)
+	assert.Equal(t, 1151, outer.Frames[0].Line)
+
+	assert.Equal(t, "org.apache.spark.SparkException", trace.Exceptions[1].Class)
+	assert.Equal(t, 2, trace.Exceptions[1].OmittedFrames)
+
+	root := trace.Exceptions[2]
+	assert.Equal(t, "org.apache.hadoop.yarn.exceptions.YarnException", root.Class)
+	assert.Equal(t, "Unauthorized request to start container.\nThis token is expired. current time is 1501162708621 found 1501162567859", root.Message)
+	assert.Equal(t, "", root.Frames[0].File)
+	assert.NotEmpty(t, trace.Fingerprint)
+}
+
+func TestParseStackTrace_FingerprintIgnoresVolatileParts(t *testing.T) {
+	a := `Exception in task 0.0 in stage 1.0 (TID 3)
+java.lang.NullPointerException: value was null on mesos-slave-07
+	at sun.reflect.GeneratedMethodAccessor12.invoke(Unknown Source)
+	at org.example.Job$$Lambda$42/0x0000000800c4b840.apply(Job.scala:17)
+	at org.apache.spark.executor.Executor$TaskRunner.run(Executor.scala:274)`
+	b := `Exception in task 4.0 in stage 2.0 (TID 19)
+java.lang.NullPointerException: value was null on mesos-slave-13
+	at sun.reflect.GeneratedMethodAccessor87.invoke(Unknown Source)
+	at org.example.Job$$Lambda$57/0x00000008011f2c40.apply(Job.scala:18)
+	at org.apache.spark.executor.Executor$TaskRunner.run(Executor.scala:281)`
+	different := `Exception in task 0.0 in stage 1.0 (TID 3)
+java.lang.IllegalStateException: value was null on mesos-slave-07
+	at org.apache.spark.executor.Executor$TaskRunner.run(Executor.scala:274)`
+
+	traceA, ok := parser.ParseStackTrace(a)
+	require.True(t, ok)
+	traceB, ok := parser.ParseStackTrace(b)
+	require.True(t, ok)
+	traceDifferent, ok := parser.ParseStackTrace(different)
+	require.True(t, ok)
+
+	assert.Equal(t, traceA.Fingerprint, traceB.Fingerprint)
+	assert.NotEqual(t, traceA.Fingerprint, traceDifferent.Fingerprint)
+}
+
+func TestParseStackTrace_NoFrames(t *testing.T) {
+	_, ok := parser.ParseStackTrace("Lost task 0.0 in stage 1.0: java.io.IOException: Connection reset by peer")
+	assert.False(t, ok)
+
+	assert.Equal(t,
+		parser.MessageFingerprint("Lost executor 3 on mesos-slave-07: Container killed"),
+		parser.MessageFingerprint("Lost executor 11 on mesos-slave-12: Container killed"),
+	)
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogMessage is a syslog frame parsed per RFC 5424 or, failing that, the BSD format of RFC 3164.
+type SyslogMessage struct {
+	// Timestamp, Level from the severity, Component from the app name (TAG in RFC 3164), InitialContent is the message
+	Header   HeaderInfo
+	Facility int
+	Severity int
+	Hostname string // Empty if the sender left it out
+	ProcID   string
+	MsgID    string // RFC 5424 only
+}
+
+// syslogLevels maps severities to the levels used by log files: emerg, alert and crit are FATAL, notice is INFO.
+var syslogLevels = [8]string{"FATAL", "FATAL", "FATAL", "ERROR", "WARN", "INFO", "INFO", "DEBUG"}
+
+var (
+	priorityRegex = regexp.MustCompile(`^<(\d{1,3})>`)
+	// Groups: 1:Tag, 2:ProcID. The tag ends at "[", ":" or a space.
+	bsdTagRegex = regexp.MustCompile(`^([^\s\[:]+)(?:\[([^\]]*)\])?:\s?`)
+)
+
+// ParseSyslog parses one syslog message, without its transport framing.
+func ParseSyslog(frame string) (*SyslogMessage, error) {
+	frame = strings.TrimRight(frame, "\r\n\x00")
+	matches := priorityRegex.FindStringSubmatch(frame)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid syslog message: missing priority")
+	}
+	priority, _ := strconv.Atoi(matches[1])
+	if priority > 191 {
+		return nil, fmt.Errorf("invalid syslog message: priority %d out of range", priority)
+	}
+	msg := &SyslogMessage{Facility: priority / 8, Severity: priority % 8}
+	msg.Header.Level = syslogLevels[msg.Severity]
+	rest := frame[len(matches[0]):]
+
+	if after, ok := strings.CutPrefix(rest, "1 "); ok {
+		parseSyslog5424(msg, after)
+	} else {
+		parseSyslog3164(msg, rest)
+	}
+	return msg, nil
+}
+
+// parseSyslog5424 parses "TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]", where "-" is nil.
+func parseSyslog5424(msg *SyslogMessage, rest string) {
+	fields := make([]string, 5)
+	for i := range fields {
+		var field string
+		field, rest, _ = strings.Cut(rest, " ")
+		if field != "-" {
+			fields[i] = field
+		}
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		msg.Header.Unparsed = true
+	} else {
+		msg.Header.Timestamp = timestamp.UTC()
+		msg.Header.Zoned = true
+	}
+	msg.Hostname = fields[1]
+	msg.Header.Component = fields[2]
+	msg.ProcID = fields[3]
+	msg.MsgID = fields[4]
+
+	rest = skipStructuredData(rest)
+	msg.Header.InitialContent = strings.TrimPrefix(strings.TrimPrefix(rest, " "), "\ufeff")
+}
+
+// skipStructuredData drops "-" or the [id param="value"...] elements at the start of rest.
+// Quoted values may contain escaped quotes and brackets.
+func skipStructuredData(rest string) string {
+	if after, ok := strings.CutPrefix(rest, "-"); ok {
+		return after
+	}
+	for strings.HasPrefix(rest, "[") {
+		inQuotes := false
+		end := -1
+		for i := 1; i < len(rest) && end < 0; i++ {
+			switch {
+			case rest[i] == '\\' && inQuotes:
+				i++
+			case rest[i] == '"':
+				inQuotes = !inQuotes
+			case rest[i] == ']' && !inQuotes:
+				end = i
+			}
+		}
+		if end < 0 {
+			// Unterminated: treat everything as the message
+			return rest
+		}
+		rest = rest[end+1:]
+	}
+	return rest
+}
+
+// bsdTimestampLayouts are the RFC 3164 timestamp with the day padded to two characters, as the RFC
+// requires, and unpadded, as some senders write it ("Feb 5 10:00:00").
+var bsdTimestampLayouts = []string{time.Stamp, "Jan 2 15:04:05"}
+
+// parseSyslog3164 parses "Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG". Senders disagree on everything
+// but the timestamp, so a missing hostname or tag leaves the remaining text as the message.
+func parseSyslog3164(msg *SyslogMessage, rest string) {
+	msg.Header.Unparsed = true
+	for _, layout := range bsdTimestampLayouts {
+		if len(rest) < len(layout) {
+			continue
+		}
+		if timestamp, err := time.Parse(layout, rest[:len(layout)]); err == nil {
+			msg.Header.Timestamp = timestamp.UTC()
+			msg.Header.YearMissing = true
+			msg.Header.Unparsed = false
+			rest = strings.TrimPrefix(rest[len(layout):], " ")
+			break
+		}
+	}
+
+	if !msg.Header.Unparsed {
+		// The hostname is the next word unless that word is already the tag
+		if host, after, ok := strings.Cut(rest, " "); ok && !strings.ContainsAny(host, "[:") {
+			msg.Hostname = host
+			rest = after
+		}
+	}
+	if matches := bsdTagRegex.FindStringSubmatch(rest); matches != nil {
+		msg.Header.Component = matches[1]
+		msg.ProcID = matches[2]
+		rest = rest[len(matches[0]):]
+	}
+	msg.Header.InitialContent = rest
+}
@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/parser"
+)
+
+func TestParseSyslog_RFC5424(t *testing.T) {
+	msg, err := parser.ParseSyslog(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="App\]lication"] An application event log entry`)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, msg.Facility)
+	assert.Equal(t, 5, msg.Severity)
+	assert.Equal(t, "mymachine.example.com", msg.Hostname)
+	assert.Equal(t, "1234", msg.ProcID)
+	assert.Equal(t, "ID47", msg.MsgID)
+	assert.Equal(t, "INFO", msg.Header.Level)
+	assert.Equal(t, "evntslog", msg.Header.Component)
+	assert.Equal(t, "An application event log entry", msg.Header.InitialContent)
+	assert.True(t, msg.Header.Zoned)
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC), msg.Header.Timestamp)
+
+	msg, err = parser.ParseSyslog("<11>1 - - - - - -")
+	require.NoError(t, err)
+	assert.Equal(t, "ERROR", msg.Header.Level)
+	assert.True(t, msg.Header.Unparsed)
+	assert.Empty(t, msg.Hostname)
+	assert.Empty(t, msg.Header.InitialContent)
+}
+
+func TestParseSyslog_RFC3164(t *testing.T) {
+	msg, err := parser.ParseSyslog("<34>Oct  3 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, msg.Facility)
+	assert.Equal(t, "FATAL", msg.Header.Level)
+	assert.Equal(t, "mymachine", msg.Hostname)
+	assert.Equal(t, "su", msg.Header.Component)
+	assert.Equal(t, "230", msg.ProcID)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", msg.Header.InitialContent)
+	assert.True(t, msg.Header.YearMissing)
+	assert.Equal(t, time.October, msg.Header.Timestamp.Month())
+	assert.Equal(t, 3, msg.Header.Timestamp.Day())
+
+	// No hostname
+	msg, err = parser.ParseSyslog("<13>Feb  5 17:32:18 sshd: Accepted publickey")
+	require.NoError(t, err)
+	assert.Empty(t, msg.Hostname)
+	assert.Equal(t, "sshd", msg.Header.Component)
+	assert.Equal(t, "Accepted publickey", msg.Header.InitialContent)
+
+	// Day not padded
+	msg, err = parser.ParseSyslog("<13>Feb 5 10:00:00 web01 nginx: started")
+	require.NoError(t, err)
+	assert.False(t, msg.Header.Unparsed)
+	assert.Equal(t, 5, msg.Header.Timestamp.Day())
+	assert.Equal(t, 10, msg.Header.Timestamp.Hour())
+	assert.Equal(t, "web01", msg.Hostname)
+	assert.Equal(t, "nginx", msg.Header.Component)
+	assert.Equal(t, "started", msg.Header.InitialContent)
+
+	// No timestamp
+	msg, err = parser.ParseSyslog("<13>kernel: Out of memory")
+	require.NoError(t, err)
+	assert.True(t, msg.Header.Unparsed)
+	assert.Equal(t, "kernel", msg.Header.Component)
+
+	_, err = parser.ParseSyslog("no priority")
+	assert.Error(t, err)
+}
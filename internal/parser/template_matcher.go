@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"skeleton-internship-backend/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+const templateWildcard = "<*>"
+
+// EventTemplate is one row of event_templates.csv, e.g. E7 -> "Successfully started service <*> on port <*>."
+type EventTemplate struct {
+	EventID  string `json:"event_id"`
+	Template string `json:"template"`
+}
+
+type TemplateMatcher interface {
+	// Match returns the event ID of the first template matching content and the values captured by its wildcards.
+	Match(content string) (eventID string, params []string, ok bool)
+	Templates() []EventTemplate
+}
+
+type compiledTemplate struct {
+	EventTemplate
+	prefix   string // Literal text before the first wildcard, used to skip the regex cheaply
+	literals int    // Number of literal characters, more specific templates are tried first
+	regex    *regexp.Regexp
+}
+
+type templateMatcher struct {
+	templates []compiledTemplate
+}
+
+func NewTemplateMatcher(cfg *config.Config) (TemplateMatcher, error) {
+	path := cfg.LogProcessor.TemplateFile
+	if path == "" {
+		log.Warn().Msg("No event template file configured, template matching disabled.")
+		return &templateMatcher{}, nil
+	}
+
+	templates, err := LoadEventTemplates(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Warn().Str("file", path).Msg("Event template file not found, template matching disabled.")
+			return &templateMatcher{}, nil
+		}
+		log.Error().Err(err).Str("file", path).Msg("Failed to load event templates")
+		return nil, err
+	}
+
+	matcher, err := NewTemplateMatcherFromTemplates(templates)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("file", path).Int("template_count", len(templates)).Msg("Event templates loaded")
+	return matcher, nil
+}
+
+func NewTemplateMatcherFromTemplates(templates []EventTemplate) (TemplateMatcher, error) {
+	m := &templateMatcher{templates: make([]compiledTemplate, 0, len(templates))}
+	for _, t := range templates {
+		regex, err := compileTemplate(t.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile template %s: %w", t.EventID, err)
+		}
+		prefix, _, _ := strings.Cut(t.Template, templateWildcard)
+		m.templates = append(m.templates, compiledTemplate{
+			EventTemplate: t,
+			prefix:        prefix,
+			literals:      len(strings.ReplaceAll(t.Template, templateWildcard, "")),
+			regex:         regex,
+		})
+	}
+	sort.SliceStable(m.templates, func(i, j int) bool {
+		return m.templates[i].literals > m.templates[j].literals
+	})
+	return m, nil
+}
+
+// LoadEventTemplates reads a CSV file with an "event_id,template" header.
+func LoadEventTemplates(path string) ([]EventTemplate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 2
+
+	var templates []EventTemplate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %s: %w", path, err)
+		}
+		if line == 1 && record[0] == "event_id" {
+			continue
+		}
+		templates = append(templates, EventTemplate{
+			EventID:  strings.TrimSpace(record[0]),
+			Template: strings.TrimSpace(record[1]),
+		})
+	}
+	return templates, nil
+}
+
+func compileTemplate(template string) (*regexp.Regexp, error) {
+	parts := strings.Split(template, templateWildcard)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.Compile("^" + strings.Join(parts, "(.*?)") + "$")
+}
+
+func (m *templateMatcher) Match(content string) (string, []string, bool) {
+	// Only the first line carries the message; the rest is usually a stack trace
+	firstLine, _, _ := strings.Cut(content, "\n")
+	firstLine = strings.TrimSpace(firstLine)
+	if firstLine == "" {
+		return "", nil, false
+	}
+
+	for _, t := range m.templates {
+		if !strings.HasPrefix(firstLine, t.prefix) {
+			continue
+		}
+		matches := t.regex.FindStringSubmatch(firstLine)
+		if matches == nil {
+			continue
+		}
+		var params []string
+		if len(matches) > 1 {
+			params = matches[1:]
+		}
+		return t.EventID, params, true
+	}
+	return "", nil, false
+}
+
+func (m *templateMatcher) Templates() []EventTemplate {
+	templates := make([]EventTemplate, len(m.templates))
+	for i, t := range m.templates {
+		templates[i] = t.EventTemplate
+	}
+	return templates
+}
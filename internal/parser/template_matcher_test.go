@@ -0,0 +1,85 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/internal/parser"
+)
+
+func TestTemplateMatcher_MatchBundledTemplates(t *testing.T) {
+	templates, err := parser.LoadEventTemplates("../../event_templates.csv")
+	require.NoError(t, err)
+	require.Len(t, templates, 98)
+
+	matcher, err := parser.NewTemplateMatcherFromTemplates(templates)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		content    string
+		expectedID string
+		params     []string
+	}{
+		{
+			name:       "No wildcards",
+			content:    "Registered signal handlers for [TERM, HUP, INT]",
+			expectedID: "E1",
+		},
+		{
+			name:       "Two wildcards",
+			content:    "Starting executor ID 16 on host mesos-slave-26",
+			expectedID: "E15",
+			params:     []string{"16", "mesos-slave-26"},
+		},
+		{
+			name:       "Wildcards next to punctuation",
+			content:    "Running task 13.0 in stage 0.0 (TID 7)",
+			expectedID: "E21",
+			params:     []string{"13.0", "0.0", "7"},
+		},
+		{
+			name:       "Only first line of multiline content is matched",
+			content:    "Got assigned task 7\n\tat org.apache.spark.Foo(Foo.scala:1)",
+			expectedID: "E20",
+			params:     []string{"7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventID, params, ok := matcher.Match(tt.content)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedID, eventID)
+			assert.Equal(t, tt.params, params)
+		})
+	}
+}
+
+func TestTemplateMatcher_PrefersMoreSpecificTemplate(t *testing.T) {
+	matcher, err := parser.NewTemplateMatcherFromTemplates([]parser.EventTemplate{
+		{EventID: "E1", Template: "Starting <*>"},
+		{EventID: "E2", Template: "Starting remoting"},
+	})
+	require.NoError(t, err)
+
+	eventID, params, ok := matcher.Match("Starting remoting")
+	require.True(t, ok)
+	assert.Equal(t, "E2", eventID)
+	assert.Nil(t, params)
+}
+
+func TestTemplateMatcher_NoMatch(t *testing.T) {
+	matcher, err := parser.NewTemplateMatcherFromTemplates([]parser.EventTemplate{
+		{EventID: "E1", Template: "Slf4jLogger started"},
+	})
+	require.NoError(t, err)
+
+	_, _, ok := matcher.Match("Something completely different")
+	assert.False(t, ok)
+
+	_, _, ok = matcher.Match("")
+	assert.False(t, ok)
+}
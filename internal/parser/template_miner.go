@@ -0,0 +1,219 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"skeleton-internship-backend/config"
+
+	"github.com/rs/zerolog/log"
+	"go.uber.org/fx"
+)
+
+// Learned templates get IDs like L1, L2... so they never collide with the E* IDs of event_templates.csv.
+const learnedTemplatePrefix = "L"
+
+type LearnedTemplate struct {
+	EventID  string `json:"event_id"`
+	Template string `json:"template"`
+	Size     int64  `json:"size"` // Number of messages assigned to the template
+}
+
+type TemplateMiner interface {
+	// Learn assigns content to a learned template, creating or generalizing one if needed.
+	Learn(content string) (eventID string, params []string, ok bool)
+	Templates() []LearnedTemplate
+	// Save persists the learned templates if anything changed since the last save.
+	Save() error
+}
+
+type learnedTemplateFile struct {
+	NextID    int               `json:"next_id"`
+	Templates []LearnedTemplate `json:"templates"`
+}
+
+type drainTemplateMiner struct {
+	mu       sync.Mutex
+	saveMu   sync.Mutex // Serializes writers of the template file
+	tree     *drainTree
+	clusters []*drainCluster
+	nextID   int
+	dirty    bool
+	filePath string
+}
+
+func NewTemplateMiner(lc fx.Lifecycle, cfg *config.Config) (TemplateMiner, error) {
+	m := newDrainTemplateMiner(cfg.LogProcessor)
+	if err := m.load(); err != nil {
+		log.Error().Err(err).Str("file", m.filePath).Msg("Failed to load learned templates")
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			log.Info().Msg("Saving learned templates...")
+			return m.Save()
+		},
+	})
+	log.Info().
+		Str("file", m.filePath).
+		Int("template_count", len(m.clusters)).
+		Int("depth", m.tree.depth).
+		Float64("sim_threshold", m.tree.simThreshold).
+		Msg("Template miner initialized")
+	return m, nil
+}
+
+func newDrainTemplateMiner(cfg config.LogProcessorConfig) *drainTemplateMiner {
+	return &drainTemplateMiner{
+		tree:     newDrainTree(cfg.DrainDepth, cfg.DrainSimThreshold, cfg.DrainMaxChildren),
+		nextID:   1,
+		filePath: cfg.LearnedTemplateFile,
+	}
+}
+
+func (m *drainTemplateMiner) Learn(content string) (string, []string, bool) {
+	tokens := tokenize(content)
+	if len(tokens) == 0 {
+		return "", nil, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cluster := m.tree.search(tokens)
+	if cluster == nil {
+		cluster = &drainCluster{
+			id:     learnedTemplatePrefix + strconv.Itoa(m.nextID),
+			tokens: tokens,
+		}
+		m.nextID++
+		m.tree.insert(cluster)
+		m.clusters = append(m.clusters, cluster)
+		log.Debug().Str("event_id", cluster.id).Str("template", cluster.template()).Msg("Learned new log template")
+	} else if mergeTemplate(cluster.tokens, tokens) {
+		log.Debug().Str("event_id", cluster.id).Str("template", cluster.template()).Msg("Generalized learned log template")
+	}
+	cluster.size++
+	m.dirty = true
+
+	return cluster.id, extractParams(cluster.tokens, content), true
+}
+
+func (m *drainTemplateMiner) Templates() []LearnedTemplate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot()
+}
+
+func (m *drainTemplateMiner) snapshot() []LearnedTemplate {
+	templates := make([]LearnedTemplate, len(m.clusters))
+	for i, c := range m.clusters {
+		templates[i] = LearnedTemplate{
+			EventID:  c.id,
+			Template: c.template(),
+			Size:     c.size,
+		}
+	}
+	return templates
+}
+
+func (m *drainTemplateMiner) Save() error {
+	if m.filePath == "" {
+		return nil
+	}
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	if !m.dirty {
+		m.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(learnedTemplateFile{
+		NextID:    m.nextID,
+		Templates: m.snapshot(),
+	}, "", "  ")
+	m.dirty = false
+	m.mu.Unlock()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal learned templates")
+		return err
+	}
+
+	tempFilePath := m.filePath + ".tmp"
+	if err := os.WriteFile(tempFilePath, data, 0644); err != nil {
+		log.Error().Err(err).Str("file", tempFilePath).Msg("Failed to write temporary learned template file")
+		m.markDirty()
+		return err
+	}
+	if err := os.Rename(tempFilePath, m.filePath); err != nil {
+		log.Error().Err(err).Str("from", tempFilePath).Str("to", m.filePath).Msg("Failed to rename learned template file")
+		_ = os.Remove(tempFilePath)
+		m.markDirty()
+		return err
+	}
+	log.Debug().Str("file", m.filePath).Msg("Saved learned templates")
+	return nil
+}
+
+func (m *drainTemplateMiner) markDirty() {
+	m.mu.Lock()
+	m.dirty = true
+	m.mu.Unlock()
+}
+
+func (m *drainTemplateMiner) load() error {
+	if m.filePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Warn().Str("file", m.filePath).Msg("Learned template file not found, starting fresh.")
+			return nil
+		}
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var stored learnedTemplateFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal learned templates: %w", err)
+	}
+	// Insert in ID order so the tree is rebuilt the same way it was grown
+	sort.SliceStable(stored.Templates, func(i, j int) bool {
+		return learnedIDNumber(stored.Templates[i].EventID) < learnedIDNumber(stored.Templates[j].EventID)
+	})
+
+	m.nextID = max(stored.NextID, 1)
+	for _, t := range stored.Templates {
+		cluster := &drainCluster{
+			id:     t.EventID,
+			tokens: strings.Fields(t.Template),
+			size:   t.Size,
+		}
+		if len(cluster.tokens) == 0 {
+			continue
+		}
+		m.tree.insert(cluster)
+		m.clusters = append(m.clusters, cluster)
+		m.nextID = max(m.nextID, learnedIDNumber(t.EventID)+1)
+	}
+	return nil
+}
+
+func learnedIDNumber(eventID string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(eventID, learnedTemplatePrefix))
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/config"
+)
+
+func newTestMiner(t *testing.T, filePath string) *drainTemplateMiner {
+	t.Helper()
+	m := newDrainTemplateMiner(config.LogProcessorConfig{
+		LearnedTemplateFile: filePath,
+		DrainDepth:          4,
+		DrainSimThreshold:   0.4,
+		DrainMaxChildren:    100,
+	})
+	require.NoError(t, m.load())
+	return m
+}
+
+func TestDrainTemplateMiner_LearnAndGeneralize(t *testing.T) {
+	m := newTestMiner(t, "")
+
+	id1, params, ok := m.Learn("Removed broadcast_12 on disk of mesos-slave-07")
+	require.True(t, ok)
+	assert.Equal(t, "L1", id1)
+	assert.Equal(t, []string{"broadcast_12", "mesos-slave-07"}, params)
+
+	// Same shape, one differing word: joins L1 and turns that word into a wildcard
+	id2, params, ok := m.Learn("Removed broadcast_13 on memory of worker-a")
+	require.True(t, ok)
+	assert.Equal(t, id1, id2)
+	assert.Equal(t, []string{"broadcast_13", "memory", "worker-a"}, params)
+
+	id3, _, ok := m.Learn("Lost connection to the shuffle service")
+	require.True(t, ok)
+	assert.Equal(t, "L2", id3)
+
+	templates := m.Templates()
+	require.Len(t, templates, 2)
+	assert.Equal(t, "Removed <*> on <*> of <*>", templates[0].Template)
+	assert.Equal(t, int64(2), templates[0].Size)
+
+	_, _, ok = m.Learn("   ")
+	assert.False(t, ok)
+}
+
+func TestDrainTemplateMiner_PersistsStableIDs(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "learned_templates.json")
+
+	m := newTestMiner(t, filePath)
+	m.Learn("Lost connection to the shuffle service")
+	m.Learn("Removed broadcast_12 on disk of mesos-slave-07")
+	require.NoError(t, m.Save())
+
+	reloaded := newTestMiner(t, filePath)
+	assert.Equal(t, m.Templates(), reloaded.Templates())
+
+	id, _, _ := reloaded.Learn("Removed broadcast_99 on disk of mesos-slave-01")
+	assert.Equal(t, "L2", id)
+	id, _, _ = reloaded.Learn("Executor heartbeat timed out after waiting")
+	assert.Equal(t, "L3", id)
+}
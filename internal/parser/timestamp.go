@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"skeleton-internship-backend/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// futureTolerance is how far ahead of now a year-less timestamp may be before it is assumed
+// to belong to the previous year (e.g. a "Dec 31" line read on Jan 1).
+const futureTolerance = 24 * time.Hour
+
+// TimestampResolver turns the wall-clock timestamps parsers produce into UTC instants,
+// using the timezone configured for the log's source directory.
+type TimestampResolver struct {
+	defaultLocation *time.Location
+	rules           dirRules
+	locations       map[string]*time.Location
+	now             func() time.Time
+}
+
+func NewTimestampResolver(cfg *config.Config) (*TimestampResolver, error) {
+	r := &TimestampResolver{
+		locations: make(map[string]*time.Location),
+		now:       time.Now,
+	}
+
+	defaultLocation, err := r.loadLocation(cfg.LogProcessor.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	r.defaultLocation = defaultLocation
+
+	for _, name := range cfg.LogProcessor.TimezoneRules {
+		if _, err := r.loadLocation(name); err != nil {
+			return nil, err
+		}
+	}
+	if r.rules, err = newDirRules(cfg.LogProcessor.TimezoneRules); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("default_timezone", r.defaultLocation.String()).Int("rules", len(r.rules)).Msg("Timestamp resolver initialized")
+	return r, nil
+}
+
+func (r *TimestampResolver) loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = "UTC"
+	}
+	if loc, ok := r.locations[name]; ok {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	r.locations[name] = loc
+	return loc, nil
+}
+
+// LocationFor returns the timezone lines in filePath were written in.
+func (r *TimestampResolver) LocationFor(filePath string) *time.Location {
+	if name, ok := r.rules.lookup(filepath.Dir(filePath)); ok {
+		return r.locations[name]
+	}
+	return r.defaultLocation
+}
+
+// Resolve returns the UTC timestamp for a parsed header and whether it was inferred rather than parsed.
+// Unparseable timestamps inherit previous, the timestamp of the entry before it in the same source.
+func (r *TimestampResolver) Resolve(header *HeaderInfo, loc *time.Location, previous time.Time) (time.Time, bool) {
+	if header.Unparsed {
+		return previous, true
+	}
+
+	ts := header.Timestamp
+	if !header.Zoned {
+		ts = time.Date(ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc)
+	}
+	if header.YearMissing {
+		ts = r.inferYear(ts, loc, previous)
+	}
+	return ts.UTC(), false
+}
+
+// inferYear places a year-less timestamp in the year closest to the previous entry, or,
+// for the first entry of a source, in the latest year that does not put it in the future.
+func (r *TimestampResolver) inferYear(ts time.Time, loc *time.Location, previous time.Time) time.Time {
+	withYear := func(year int) time.Time {
+		return time.Date(year, ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc)
+	}
+
+	if previous.IsZero() {
+		now := r.now().In(loc)
+		candidate := withYear(now.Year())
+		if candidate.Sub(now) > futureTolerance {
+			candidate = withYear(now.Year() - 1)
+		}
+		return candidate
+	}
+
+	const halfYear = 183 * 24 * time.Hour
+	ref := previous.In(loc)
+	candidate := withYear(ref.Year())
+	switch {
+	case ref.Sub(candidate) > halfYear: // Dec 31 -> Jan 1 rollover
+		candidate = withYear(ref.Year() + 1)
+	case candidate.Sub(ref) > halfYear: // Slightly out-of-order line just after a rollover
+		candidate = withYear(ref.Year() - 1)
+	}
+	return candidate
+}
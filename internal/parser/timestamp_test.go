@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/parser"
+)
+
+func newTestResolver(t *testing.T, timezone string, rules map[string]string) *parser.TimestampResolver {
+	t.Helper()
+	r, err := parser.NewTimestampResolver(&config.Config{
+		LogProcessor: config.LogProcessorConfig{Timezone: timezone, TimezoneRules: rules},
+	})
+	require.NoError(t, err)
+	return r
+}
+
+func TestTimestampResolver_InvalidTimezone(t *testing.T) {
+	_, err := parser.NewTimestampResolver(&config.Config{
+		LogProcessor: config.LogProcessorConfig{Timezone: "Mars/Olympus_Mons"},
+	})
+	assert.Error(t, err)
+}
+
+func TestTimestampResolver_LocalizesPerSource(t *testing.T) {
+	r := newTestResolver(t, "UTC", map[string]string{"application_*": "Asia/Ho_Chi_Minh"})
+	header := &parser.HeaderInfo{Timestamp: time.Date(2017, 7, 27, 21, 35, 58, 0, time.UTC)}
+
+	ts, inferred := r.Resolve(header, r.LocationFor("/logs/application_1/container_1.log"), time.Time{})
+	assert.False(t, inferred)
+	assert.Equal(t, time.Date(2017, 7, 27, 14, 35, 58, 0, time.UTC), ts)
+
+	ts, _ = r.Resolve(header, r.LocationFor("/logs/nodemanager/yarn.log"), time.Time{})
+	assert.Equal(t, time.Date(2017, 7, 27, 21, 35, 58, 0, time.UTC), ts)
+}
+
+func TestTimestampResolver_KeepsExplicitOffset(t *testing.T) {
+	r := newTestResolver(t, "Asia/Ho_Chi_Minh", nil)
+	instant := time.Date(2017, 7, 27, 14, 35, 58, 0, time.UTC)
+
+	ts, inferred := r.Resolve(&parser.HeaderInfo{Timestamp: instant, Zoned: true}, r.LocationFor("/logs/app/a.log"), time.Time{})
+	assert.False(t, inferred)
+	assert.Equal(t, instant, ts)
+}
+
+func TestTimestampResolver_InheritsUnparsed(t *testing.T) {
+	r := newTestResolver(t, "", nil)
+	previous := time.Date(2017, 7, 27, 14, 35, 58, 0, time.UTC)
+
+	ts, inferred := r.Resolve(&parser.HeaderInfo{Unparsed: true}, time.UTC, previous)
+	assert.True(t, inferred)
+	assert.Equal(t, previous, ts)
+}
+
+func TestTimestampResolver_InfersYearAcrossRollover(t *testing.T) {
+	r := newTestResolver(t, "", nil)
+	previous := time.Date(2016, 12, 31, 23, 59, 50, 0, time.UTC)
+
+	// Parsers leave the year at zero when the format does not carry one
+	header := &parser.HeaderInfo{Timestamp: time.Date(0, 1, 1, 0, 0, 5, 0, time.UTC), YearMissing: true}
+	ts, inferred := r.Resolve(header, time.UTC, previous)
+	assert.False(t, inferred)
+	assert.Equal(t, time.Date(2017, 1, 1, 0, 0, 5, 0, time.UTC), ts)
+
+	header = &parser.HeaderInfo{Timestamp: time.Date(0, 12, 31, 23, 59, 55, 0, time.UTC), YearMissing: true}
+	ts, _ = r.Resolve(header, time.UTC, previous)
+	assert.Equal(t, time.Date(2016, 12, 31, 23, 59, 55, 0, time.UTC), ts)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+)
+
+var ErrApplicationNotFound = errors.New("application not found")
+
+type ApplicationRepository interface {
+	ListApplications(ctx context.Context, req dto.ApplicationSearchRequest) (*dto.ApplicationSearchResponse, error)
+	GetApplication(ctx context.Context, id string) (*model.Application, error)
+}
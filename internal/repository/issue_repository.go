@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+)
+
+var ErrIssueNotFound = errors.New("issue not found")
+
+type IssueRepository interface {
+	ListIssues(ctx context.Context, req dto.IssueListRequest) (*dto.IssueListResponse, error)
+	GetIssue(ctx context.Context, id int64) (*model.Issue, error)
+}
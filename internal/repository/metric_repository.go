@@ -8,6 +8,5 @@ import (
 type MetricRepository interface {
 	GetSummaryMetrics(ctx context.Context, req dto.MetricSummaryRequest) (*dto.MetricSummaryResponse, error)
 	GetTimeseriesMetrics(ctx context.Context, req dto.MetricTimeseriesRequest) (*dto.MetricTimeseriesResponse, error)
-	GetDistinctApplications(ctx context.Context, req dto.ApplicationListRequest) (*dto.ApplicationListResponse, error)
 	GetDistributionMetrics(ctx context.Context, req dto.MetricDistributionRequest) (*dto.MetricDistributionResponse, error)
 }
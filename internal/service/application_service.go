@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ApplicationService interface {
+	ListApplications(ctx context.Context, req dto.ApplicationSearchRequest) (*dto.ApplicationSearchResponse, error)
+	GetApplication(ctx context.Context, id string) (*model.Application, error)
+}
+
+type applicationService struct {
+	appRepo repository.ApplicationRepository
+}
+
+func NewApplicationService(appRepo repository.ApplicationRepository) ApplicationService {
+	return &applicationService{
+		appRepo: appRepo,
+	}
+}
+
+func (s *applicationService) ListApplications(ctx context.Context, req dto.ApplicationSearchRequest) (*dto.ApplicationSearchResponse, error) {
+	if req.StartTime.IsZero() || req.EndTime.IsZero() {
+		return nil, errors.New("invalid time range: startTime and endTime are required")
+	}
+	if req.EndTime.Before(req.StartTime) {
+		return nil, errors.New("invalid time range: endTime cannot be before startTime")
+	}
+	req.Status = strings.ToUpper(req.Status)
+	switch req.Status {
+	case "", model.AppStatusRunning, model.AppStatusSucceeded, model.AppStatusFailed, model.AppStatusKilled:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", req.Status)
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 || req.Size > 1000 {
+		req.Size = 50
+	}
+
+	log.Info().
+		Time("start", req.StartTime).
+		Time("end", req.EndTime).
+		Str("status", req.Status).
+		Int("page", req.Page).
+		Int("size", req.Size).
+		Msg("Listing applications")
+	return s.appRepo.ListApplications(ctx, req)
+}
+
+func (s *applicationService) GetApplication(ctx context.Context, id string) (*model.Application, error) {
+	return s.appRepo.GetApplication(ctx, id)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/kafka"
+	"skeleton-internship-backend/internal/model"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxReplayIDs bounds how many dead letters one replay request reads back from Kafka.
+const maxReplayIDs = 500
+
+type DeadLetterService interface {
+	ListDeadLetters(ctx context.Context, req dto.DeadLetterListRequest) (*dto.DeadLetterListResponse, error)
+	GetDeadLetter(ctx context.Context, id string) (*model.DeadLetter, error)
+	// ReplayDeadLetters writes the original messages back to the log topic, in the order of req.IDs.
+	ReplayDeadLetters(ctx context.Context, req dto.DeadLetterReplayRequest) (*dto.DeadLetterReplayResponse, error)
+}
+
+type deadLetterService struct {
+	deadLetters kafka.DeadLetterQueue
+}
+
+func NewDeadLetterService(deadLetters kafka.DeadLetterQueue) DeadLetterService {
+	return &deadLetterService{
+		deadLetters: deadLetters,
+	}
+}
+
+func (s *deadLetterService) ListDeadLetters(ctx context.Context, req dto.DeadLetterListRequest) (*dto.DeadLetterListResponse, error) {
+	if req.BeforeOffset > 0 && req.Partition < 0 {
+		return nil, fmt.Errorf("invalid query: beforeOffset requires partition")
+	}
+	if req.Limit <= 0 || req.Limit > 500 {
+		req.Limit = 50
+	}
+
+	letters, total, err := s.deadLetters.List(ctx, kafka.DeadLetterQuery{
+		Partition:    req.Partition,
+		BeforeOffset: req.BeforeOffset,
+		Limit:        req.Limit,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list dead letters")
+		return nil, err
+	}
+	if letters == nil {
+		letters = []model.DeadLetter{}
+	}
+	return &dto.DeadLetterListResponse{DeadLetters: letters, TotalCount: total}, nil
+}
+
+func (s *deadLetterService) GetDeadLetter(ctx context.Context, id string) (*model.DeadLetter, error) {
+	partition, offset, err := parseDeadLetterID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.deadLetters.Get(ctx, partition, offset)
+}
+
+func (s *deadLetterService) ReplayDeadLetters(ctx context.Context, req dto.DeadLetterReplayRequest) (*dto.DeadLetterReplayResponse, error) {
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("invalid replay request: ids is required")
+	}
+	if len(req.IDs) > maxReplayIDs {
+		return nil, fmt.Errorf("invalid replay request: at most %d ids per request", maxReplayIDs)
+	}
+
+	letters := make([]model.DeadLetter, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		partition, offset, err := parseDeadLetterID(id)
+		if err != nil {
+			return nil, err
+		}
+		letter, err := s.deadLetters.Get(ctx, partition, offset)
+		if err != nil {
+			return nil, fmt.Errorf("dead letter %s: %w", id, err)
+		}
+		letters = append(letters, *letter)
+	}
+	if err := s.deadLetters.Replay(ctx, letters); err != nil {
+		return nil, fmt.Errorf("failed to replay dead letters: %w", err)
+	}
+
+	replayed := make([]string, len(letters))
+	for i, letter := range letters {
+		replayed[i] = letter.ID
+	}
+	log.Info().Strs("ids", replayed).Msg("Replayed dead letters")
+	return &dto.DeadLetterReplayResponse{Replayed: replayed}, nil
+}
+
+// parseDeadLetterID splits a model.DeadLetter ID, "<partition>-<offset>".
+func parseDeadLetterID(id string) (int, int64, error) {
+	partitionStr, offsetStr, ok := strings.Cut(strings.TrimSpace(id), "-")
+	partition, errPartition := strconv.Atoi(partitionStr)
+	offset, errOffset := strconv.ParseInt(offsetStr, 10, 64)
+	if !ok || errPartition != nil || errOffset != nil || partition < 0 || offset < 0 {
+		return 0, 0, fmt.Errorf("invalid dead letter id %q: expected <partition>-<offset>", id)
+	}
+	return partition, offset, nil
+}
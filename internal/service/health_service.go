@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/filestate"
+	"skeleton-internship-backend/internal/kafka"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+type HealthService interface {
+	// Ready checks every dependency concurrently, each bounded by SERVER_READINESS_TIMEOUT.
+	Ready(ctx context.Context) dto.ReadinessResponse
+}
+
+// dependencyCheck is one dependency of the readiness check.
+type dependencyCheck struct {
+	name     string
+	required bool
+	ping     func(ctx context.Context) error
+	cacheFor time.Duration // How long a result is reused, for dependencies too costly to ping on every probe
+
+	mu        sync.Mutex
+	last      dto.DependencyStatus
+	checkedAt time.Time
+}
+
+// llmCheckInterval spares the LLM API a call on every readiness probe.
+const llmCheckInterval = 60 * time.Second
+
+type healthService struct {
+	checks  []*dependencyCheck
+	timeout time.Duration
+}
+
+func NewHealthService(cfg *config.Config, esClient *elasticsearch.Client, pool *pgxpool.Pool, producer kafka.LogProducer, llmService LLMService, fileState filestate.Manager) HealthService {
+	queue := "kafka"
+	if _, embedded := producer.(*kafka.EmbeddedQueue); embedded {
+		queue = "embedded_queue"
+	}
+	timeout := cfg.Server.ReadinessTimeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &healthService{
+		checks: []*dependencyCheck{
+			{name: "elasticsearch", required: true, ping: func(ctx context.Context) error { return pingElasticsearch(ctx, esClient) }},
+			{name: "timescaledb", required: true, ping: pool.Ping},
+			{name: queue, required: true, ping: producer.Ping},
+			{name: "state_file", required: true, ping: func(context.Context) error { return checkStateFile(fileState.GetStateFilePath()) }},
+			// Only natural language queries need the LLM
+			{name: "llm", required: false, ping: llmService.Ping, cacheFor: llmCheckInterval},
+		},
+		timeout: timeout,
+	}
+}
+
+func (s *healthService) Ready(ctx context.Context) dto.ReadinessResponse {
+	dependencies := make([]dto.DependencyStatus, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check *dependencyCheck) {
+			defer wg.Done()
+			dependencies[i] = check.status(ctx, s.timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := dto.ReadinessOK
+	for _, dependency := range dependencies {
+		if dependency.Status == dto.DependencyUp {
+			continue
+		}
+		if dependency.Required {
+			status = dto.ReadinessUnavailable
+			break
+		}
+		status = dto.ReadinessDegraded
+	}
+	return dto.ReadinessResponse{Status: status, Dependencies: dependencies}
+}
+
+// status pings the dependency, or returns the last result while it is younger than cacheFor.
+func (c *dependencyCheck) status(ctx context.Context, timeout time.Duration) dto.DependencyStatus {
+	if c.cacheFor > 0 {
+		// Concurrent probes wait for a single ping
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.cacheFor {
+			return c.last
+		}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	err := c.ping(checkCtx)
+	dependency := dto.DependencyStatus{
+		Name:      c.name,
+		Status:    dto.DependencyUp,
+		Required:  c.required,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("dependency", c.name).Msg("Readiness check failed")
+		dependency.Status = dto.DependencyDown
+		dependency.Error = err.Error()
+	}
+	// A probe that went away says nothing about the dependency
+	if c.cacheFor > 0 && ctx.Err() == nil {
+		c.last = dependency
+		c.checkedAt = time.Now()
+	}
+	return dependency
+}
+
+func pingElasticsearch(ctx context.Context, client *elasticsearch.Client) error {
+	res, err := client.Ping(client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch ping returned %s", res.Status())
+	}
+	return nil
+}
+
+// checkStateFile makes sure the producer can save its state: the file, when it exists, is a regular
+// file, and its directory is writable, as the state is written to a temporary file renamed over it.
+func checkStateFile(path string) error {
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("state file directory is not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
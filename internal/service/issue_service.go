@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type IssueService interface {
+	ListIssues(ctx context.Context, req dto.IssueListRequest) (*dto.IssueListResponse, error)
+	GetIssue(ctx context.Context, id int64) (*model.Issue, error)
+}
+
+type issueService struct {
+	issueRepo repository.IssueRepository
+}
+
+func NewIssueService(issueRepo repository.IssueRepository) IssueService {
+	return &issueService{
+		issueRepo: issueRepo,
+	}
+}
+
+func (s *issueService) ListIssues(ctx context.Context, req dto.IssueListRequest) (*dto.IssueListResponse, error) {
+	if !req.StartTime.IsZero() && !req.EndTime.IsZero() && req.EndTime.Before(req.StartTime) {
+		return nil, errors.New("invalid time range: endTime cannot be before startTime")
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 || req.Size > 1000 {
+		req.Size = 50
+	}
+	if req.SortBy == "" {
+		req.SortBy = "last_seen"
+	}
+	if req.SortBy != "last_seen" && req.SortBy != "first_seen" && req.SortBy != "count" {
+		return nil, fmt.Errorf("invalid sortBy: %s", req.SortBy)
+	}
+	req.SortOrder = strings.ToLower(req.SortOrder)
+	if req.SortOrder != "asc" && req.SortOrder != "desc" {
+		req.SortOrder = "desc"
+	}
+
+	log.Info().
+		Time("start_time", req.StartTime).
+		Time("end_time", req.EndTime).
+		Strs("applications", req.Applications).
+		Str("query", req.Query).
+		Str("sort_by", req.SortBy).
+		Str("sort_order", req.SortOrder).
+		Int("page", req.Page).
+		Int("size", req.Size).
+		Msg("Listing issues")
+
+	return s.issueRepo.ListIssues(ctx, req)
+}
+
+func (s *issueService) GetIssue(ctx context.Context, id int64) (*model.Issue, error) {
+	return s.issueRepo.GetIssue(ctx, id)
+}
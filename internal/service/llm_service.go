@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	neturl "net/url"
 	"skeleton-internship-backend/config"
 	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/telemetry"
 	"strings"
 	"time"
 
@@ -39,25 +41,36 @@ type GeminiResponse struct {
 
 type LLMService interface {
 	AnalyzeQueryWithHistory(ctx context.Context, conversationHistory []dto.ConversationTurn, newUserQuery string, schemaContext string) (*dto.LLMAnalysisResult, error)
+	// Ping checks that the LLM endpoint is reachable and accepts the API key, without generating anything.
+	Ping(ctx context.Context) error
 }
 
 type geminiLLMService struct {
 	apiKey     string
 	httpClient *http.Client
 	modelID    string
+	metrics    *telemetry.Metrics
 }
 
-func NewGeminiLLMService(cfg *config.Config) (LLMService, error) {
+func NewGeminiLLMService(cfg *config.Config, metrics *telemetry.Metrics) (LLMService, error) {
 	return &geminiLLMService{
 		apiKey: cfg.APIKey,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
 		modelID: "gemini-1.5-flash-latest",
+		metrics: metrics,
 	}, nil
 }
 
 func (s *geminiLLMService) AnalyzeQueryWithHistory(ctx context.Context, conversationHistory []dto.ConversationTurn, newUserQuery string, schemaContext string) (*dto.LLMAnalysisResult, error) {
+	start := time.Now()
+	result, err := s.analyzeQuery(ctx, conversationHistory, newUserQuery, schemaContext)
+	s.metrics.LLMRequestDuration.WithLabelValues(telemetry.Result(err)).Observe(time.Since(start).Seconds())
+	return result, err
+}
+
+func (s *geminiLLMService) analyzeQuery(ctx context.Context, conversationHistory []dto.ConversationTurn, newUserQuery string, schemaContext string) (*dto.LLMAnalysisResult, error) {
 	log.Info().Str("new_query", newUserQuery).Int("history_len", len(conversationHistory)).Msg("Gemini LLM Service: Analyzing query with history")
 
 	prompt := buildGeminiContents(conversationHistory, newUserQuery, schemaContext)
@@ -109,6 +122,32 @@ func (s *geminiLLMService) AnalyzeQueryWithHistory(ctx context.Context, conversa
 	return &analysisResult, nil
 }
 
+// Ping reads the metadata of the model, which needs a valid API key but costs no tokens.
+func (s *geminiLLMService) Ping(ctx context.Context) error {
+	if s.apiKey == "" {
+		return errors.New("API_KEY is not configured")
+	}
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s?key=%s", s.modelID, s.apiKey)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// The error quotes the URL, which holds the API key
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("gemini request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gemini API error: status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (s *geminiLLMService) callGeminiAPI(ctx context.Context, bodyBytes []byte) ([]byte, error) {
 	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", s.modelID, s.apiKey)
 
@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/parser"
 	"skeleton-internship-backend/internal/repository"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -15,12 +18,14 @@ type LogQueryService interface {
 }
 
 type logQueryService struct {
-	logRepo repository.LogRepository
+	logRepo    repository.LogRepository
+	fieldTypes map[string]string
 }
 
-func NewLogQueryService(logRepo repository.LogRepository) LogQueryService {
+func NewLogQueryService(logRepo repository.LogRepository, fields parser.FieldExtractor) LogQueryService {
 	return &logQueryService{
-		logRepo: logRepo,
+		logRepo:    logRepo,
+		fieldTypes: fields.FieldTypes(),
 	}
 }
 func (s *logQueryService) SearchLogs(ctx context.Context, req dto.LogSearchRequest) (*dto.LogSearchResponse, error) {
@@ -47,6 +52,9 @@ func (s *logQueryService) SearchLogs(ctx context.Context, req dto.LogSearchReque
 	for i, level := range req.Levels {
 		req.Levels[i] = strings.ToUpper(level)
 	}
+	if err := s.validateFieldFilters(req.FieldFilters); err != nil {
+		return nil, err
+	}
 
 	log.Info().
 		Time("start_time", req.StartTime).
@@ -54,6 +62,7 @@ func (s *logQueryService) SearchLogs(ctx context.Context, req dto.LogSearchReque
 		Str("query", req.Query).
 		Strs("levels", req.Levels).
 		Strs("applications", req.Applications).
+		Int("field_filters", len(req.FieldFilters)).
 		Str("sort_by", req.SortBy).
 		Str("sort_order", req.SortOrder).
 		Int("page", req.Page).
@@ -62,3 +71,27 @@ func (s *logQueryService) SearchLogs(ctx context.Context, req dto.LogSearchReque
 
 	return s.logRepo.Search(ctx, req)
 }
+
+func (s *logQueryService) validateFieldFilters(filters []dto.LogFieldFilter) error {
+	for _, f := range filters {
+		fieldType, ok := s.fieldTypes[f.Field]
+		if !ok {
+			return fmt.Errorf("invalid field filter: unknown field %q", f.Field)
+		}
+		switch f.Operator {
+		case dto.FieldOpEq:
+		case dto.FieldOpGt, dto.FieldOpGte, dto.FieldOpLt, dto.FieldOpLte:
+			if fieldType != parser.FieldTypeLong {
+				return fmt.Errorf("invalid field filter: %s only supports exact matches", f.Field)
+			}
+		default:
+			return fmt.Errorf("invalid field filter: unsupported operator %q", f.Operator)
+		}
+		if fieldType == parser.FieldTypeLong {
+			if _, err := strconv.ParseInt(f.Value, 10, 64); err != nil {
+				return fmt.Errorf("invalid field filter: %s expects an integer, got %q", f.Field, f.Value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"skeleton-internship-backend/internal/filestate"
+	"skeleton-internship-backend/internal/parser"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog/log"
+)
+
+// Compressed logs and archives come from YARN log aggregation and are complete once written, so each
+// is read start to end once and then marked complete in the file state. Decompressed streams cannot
+// seek: resuming after a partial delivery skips the already delivered bytes.
+
+// sniffSize is how much of a stream is buffered to detect its format.
+const sniffSize = 64 * 1024
+
+type decompressor func(io.Reader) (io.ReadCloser, error)
+
+var decompressors = map[string]decompressor{
+	".gz": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	},
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	},
+}
+
+var (
+	// Archive extensions with the compression of the tar stream inside
+	archiveExts = []struct{ ext, compression string }{
+		{".tar", ""},
+		{".tar.gz", ".gz"}, {".tgz", ".gz"},
+		{".tar.bz2", ".bz2"}, {".tbz2", ".bz2"},
+		{".tar.zst", ".zst"}, {".tzst", ".zst"},
+	}
+)
+
+// archiveExt returns the archive extension of name and the compression it implies; ok is false if name is not an archive.
+func archiveExt(name string) (ext, compression string, ok bool) {
+	lower := strings.ToLower(name)
+	for _, archive := range archiveExts {
+		if strings.HasSuffix(lower, archive.ext) {
+			return archive.ext, archive.compression, true
+		}
+	}
+	return "", "", false
+}
+
+func isArchive(name string) bool {
+	_, _, ok := archiveExt(name)
+	return ok
+}
+
+// isCompressedLog reports whether name is a single compressed log, such as container_..._000001.log.gz
+// or a container's stderr.gz. A log rotated by logrotate and compressed afterwards, such as
+// container_..._000001.log.2.gz, is not: its lines were already read from the live file or drained
+// from the uncompressed rotation.
+func isCompressedLog(name string) bool {
+	ext := filepath.Ext(name)
+	if _, ok := decompressors[ext]; !ok || isArchive(name) {
+		return false
+	}
+	uncompressed := strings.TrimSuffix(name, ext)
+	switch filepath.Base(uncompressed) {
+	case "stdout", "stderr":
+		return true
+	}
+	return isContainerLog(uncompressed)
+}
+
+// isLogFile reports whether name is read by the producer, plain, compressed or archived.
+func isLogFile(name string) bool {
+	return isContainerLog(name) || isCompressedLog(name) || isArchive(name)
+}
+
+// openImmutable opens a compressed file or archive unless it is complete or may still be being written.
+func (s *logProducerService) openImmutable(filePath string, saved filestate.FileState, tracked bool) (*os.File, filestate.FileState, bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, filestate.FileState{}, false, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	info, err := file.Stat()
+	if err == nil && time.Since(info.ModTime()) < s.cfg.MultilineIdleTimeout {
+		// Still being copied in; reading it now could see a cleanly cut stream
+		log.Debug().Str("file", filePath).Msg("Waiting for compressed log file to settle")
+		file.Close()
+		return nil, filestate.FileState{}, false, nil
+	}
+	var identity filestate.FileState
+	if err == nil {
+		identity, err = filestate.Identify(file, info)
+	}
+	if err == nil && tracked && saved.Complete {
+		var same bool
+		if same, err = saved.Matches(file, info); err == nil && same {
+			file.Close()
+			return nil, filestate.FileState{}, false, nil
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, filestate.FileState{}, false, fmt.Errorf("failed to identify file %s: %w", filePath, err)
+	}
+	return file, identity, true, nil
+}
+
+// processCompressedFile reads a compressed log file once. Its logical path drops the compression
+// extension, so path metadata and parser rules apply as for the uncompressed file.
+func (s *logProducerService) processCompressedFile(ctx context.Context, filePath string, state filestate.FileProcessState, emit func(fileRead)) error {
+	saved, tracked := state[filePath]
+	file, identity, ok, err := s.openImmutable(filePath, saved, tracked)
+	if err != nil || !ok {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := decompressors[filepath.Ext(filePath)](file)
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s: %w", filePath, err)
+	}
+	defer reader.Close()
+
+	if tracked && saved.Offset > 0 && saved.HeadHash == identity.HeadHash {
+		identity.Offset = saved.Offset
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file %s: %w", filePath, err)
+	}
+	stream := logStream{
+		key:     filePath,
+		path:    strings.TrimSuffix(filePath, filepath.Ext(filePath)),
+		reader:  reader,
+		modTime: info.ModTime(),
+	}
+	read, err := s.readImmutableStream(ctx, stream, identity)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	emit(read)
+	return nil
+}
+
+// processArchive reads the log files inside a tarball, tracking each member under "<archive>#<member>".
+// Each member is emitted once read. The archive itself is marked complete once every member is, and
+// then no longer opened.
+func (s *logProducerService) processArchive(ctx context.Context, archivePath string, state filestate.FileProcessState, emit func(fileRead)) error {
+	saved, tracked := state[archivePath]
+	file, identity, ok, err := s.openImmutable(archivePath, saved, tracked)
+	if err != nil || !ok {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file %s: %w", archivePath, err)
+	}
+
+	var reader io.Reader = file
+	if _, compression, _ := archiveExt(archivePath); compression != "" {
+		decompressed, err := decompressors[compression](file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", archivePath, err)
+		}
+		defer decompressed.Close()
+		reader = decompressed
+	}
+
+	membersRead := 0
+	allComplete := true
+	tarReader := tar.NewReader(reader)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg || !(isContainerLog(header.Name) || isCompressedLog(header.Name)) {
+			continue
+		}
+
+		key := archivePath + "#" + header.Name
+		memberState, memberTracked := state[key]
+		position := identity
+		if memberTracked && memberState.HeadHash == identity.HeadHash {
+			if memberState.Complete {
+				continue
+			}
+			position.Offset = memberState.Offset
+		}
+
+		var memberReader io.Reader = tarReader
+		logicalPath := archiveMemberPath(archivePath, header.Name)
+		if isCompressedLog(header.Name) {
+			decompressed, err := decompressors[path.Ext(header.Name)](tarReader)
+			if err != nil {
+				return fmt.Errorf("failed to decompress %s in %s: %w", header.Name, archivePath, err)
+			}
+			memberReader = decompressed
+			logicalPath = strings.TrimSuffix(logicalPath, filepath.Ext(logicalPath))
+		}
+
+		stream := logStream{key: key, path: logicalPath, reader: memberReader, modTime: header.ModTime}
+		read, err := s.readImmutableStream(ctx, stream, position)
+		if closer, ok := memberReader.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s in %s: %w", header.Name, archivePath, err)
+		}
+		emit(read)
+		membersRead++
+		allComplete = false
+	}
+
+	if allComplete {
+		log.Info().Str("file", archivePath).Msg("All members of archive are ingested")
+		identity.Complete = true
+		emit(fileRead{key: archivePath, final: identity})
+	}
+	log.Debug().Str("file", archivePath).Time("mod_time", info.ModTime()).Int("members_read", membersRead).Msg("Processed archive")
+	return nil
+}
+
+// sniffParser picks the parser of a stream from its first lines, without consuming them.
+func (s *logProducerService) sniffParser(streamPath string, reader *bufio.Reader) parser.LogParser {
+	sample, _ := reader.Peek(sniffSize)
+	var lines []string
+	for _, line := range strings.Split(string(sample), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return s.parsers.ForStream(streamPath, lines)
+}
+
+// readImmutableStream skips what was delivered before and reads the rest of a stream that will not grow.
+func (s *logProducerService) readImmutableStream(ctx context.Context, stream logStream, position filestate.FileState) (fileRead, error) {
+	// The decompressed lines are not on disk for the registry to sample
+	buffered := bufio.NewReaderSize(stream.reader, sniffSize)
+	stream.parser = s.sniffParser(stream.path, buffered)
+	stream.reader = buffered
+	if position.Offset > 0 {
+		if _, err := io.CopyN(io.Discard, stream.reader, position.Offset); err != nil {
+			return fileRead{}, fmt.Errorf("failed to skip to offset %d: %w", position.Offset, err)
+		}
+	}
+	linesRead, final, entries, err := s.readEntries(ctx, stream, position, true)
+	if err != nil {
+		return fileRead{}, err
+	}
+	final.Complete = true
+	return fileRead{key: stream.key, linesRead: linesRead, bytesRead: final.Offset - position.Offset, entries: entries, final: final}, nil
+}
+
+// archiveMemberPath places a member where it would be if the archive were extracted next to it.
+// Members of a tarball of a single application folder without the folder itself (application_x.tar.gz
+// holding container_*.log) are placed in that folder so path metadata still finds the application.
+func archiveMemberPath(archivePath, member string) string {
+	dir := filepath.Dir(archivePath)
+	member = filepath.FromSlash(path.Clean(member))
+	base := filepath.Base(archivePath)
+	ext, _, _ := archiveExt(base)
+	base = base[:len(base)-len(ext)]
+	if !strings.Contains(member, string(filepath.Separator)) && isApplicationDir(base) && !isApplicationDir(filepath.Base(dir)) {
+		return filepath.Join(dir, base, member)
+	}
+	return filepath.Join(dir, member)
+}
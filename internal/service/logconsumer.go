@@ -8,7 +8,12 @@ import (
 	"skeleton-internship-backend/internal/elasticsearch"
 	"skeleton-internship-backend/internal/metrics"
 	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+	"skeleton-internship-backend/internal/telemetry"
 	"skeleton-internship-backend/internal/timescaledb"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,164 +33,534 @@ type logConsumerService struct {
 	batchSize   int           // How many Kafka messages to process at once
 	maxWaitTime time.Duration // Max time to wait for batchSize messages
 	metricStore timescaledb.MetricStore
+	issueStore  timescaledb.IssueStore
+	appStore    timescaledb.ApplicationStore
 	extractor   metrics.Extractor
+	miner       parser.TemplateMiner
+	deadLetters kafka.DeadLetterQueue
+	maxAttempts int // Attempts at storing entries a store rejects before they are dead-lettered
+	metrics     *telemetry.Metrics
+	stages      []storeStage
 }
 
+// maxIssueTitleLength keeps titles of issues with very long first lines readable in lists.
+const maxIssueTitleLength = 255
+
+// maxRetryDelay caps the wait between attempts at a batch that keeps failing.
+const maxRetryDelay = 30 * time.Second
+
 func NewLogConsumerService(
 	consumer kafka.LogConsumer,
 	logStore elasticsearch.LogStore,
 	cfg *config.Config,
 	metricStore timescaledb.MetricStore,
+	issueStore timescaledb.IssueStore,
+	appStore timescaledb.ApplicationStore,
 	extractor metrics.Extractor,
+	miner parser.TemplateMiner,
+	deadLetters kafka.DeadLetterQueue,
+	metrics *telemetry.Metrics,
 ) LogConsumerService {
 	batchSize := cfg.LogProcessor.BatchSize
 	maxWaitTime := time.Duration(cfg.LogProcessor.MaxBatchWait) * time.Second
 
-	return &logConsumerService{
+	s := &logConsumerService{
 		consumer:    consumer,
 		logStore:    logStore,
 		batchSize:   batchSize,
 		maxWaitTime: maxWaitTime,
 		metricStore: metricStore,
+		issueStore:  issueStore,
+		appStore:    appStore,
 		extractor:   extractor,
+		miner:       miner,
+		deadLetters: deadLetters,
+		maxAttempts: max(cfg.Kafka.MaxDeliveryAttempts, 1),
+		metrics:     metrics,
 	}
+	s.stages = []storeStage{
+		{name: "logs", store: s.storeLogs},
+		{name: "metrics", store: s.storeMetrics},
+		{name: "issues", store: s.storeIssues},
+		{name: "application lifecycle", store: s.storeApplications},
+	}
+	return s
 }
 
 func (s *logConsumerService) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 	log.Info().Msg("Starting Log Consumer Service loop...")
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Info().Msg("Log Consumer Service loop stopping due to context cancellation.")
-			return
-		default:
-		}
+	err := s.consumer.Consume(ctx, s.consumePartition)
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msg("Log Consumer Service loop stopped")
+		return
+	}
+	log.Info().Msg("Log Consumer Service loop stopping due to context cancellation.")
+}
 
+// partitionWorker consumes one assigned partition, whose batches are stored and committed in order.
+type partitionWorker struct {
+	consumer kafka.PartitionConsumer
+	pending  *consumerBatch // Fetched batch not committed yet, retried before fetching more
+	failures int            // Consecutive failed tries at processing a batch
+
+	// For the lag, -1 until the first fetch
+	highWaterMark int64 // Offset following the last message of the partition, as of the last fetch
+	committed     int64 // Offset following the last committed message
+}
+
+// consumePartition processes batches of one partition until ctx ends, on shutdown or when the
+// partition is reassigned. A batch stored but not committed then is processed again by the new owner.
+func (s *logConsumerService) consumePartition(ctx context.Context, partition kafka.PartitionConsumer) {
+	worker := &partitionWorker{consumer: partition, highWaterMark: -1, committed: -1}
+	log.Info().Int("partition", partition.Partition()).Msg("Consuming Kafka partition")
+	lag := s.metrics.ConsumerLag.WithLabelValues(strconv.Itoa(partition.Partition()))
+	// The partition may be assigned to another member now
+	defer s.metrics.ConsumerLag.DeleteLabelValues(strconv.Itoa(partition.Partition()))
+
+	for ctx.Err() == nil {
 		// Process one batch of messages
-		err := s.processBatch(ctx)
+		err := s.processBatch(ctx, worker)
+		if worker.highWaterMark >= 0 {
+			lag.Set(float64(max(worker.highWaterMark-worker.committed, 0)))
+		}
+		if err == nil {
+			worker.failures = 0
+		} else {
+			if ctx.Err() != nil {
+				break
+			}
+			worker.failures++
+			log.Error().Err(err).Int("partition", partition.Partition()).Msg("Error processing consumer batch")
+			select {
+			case <-ctx.Done():
+			case <-time.After(worker.retryDelay()):
+			}
+		}
+	}
+	log.Info().Int("partition", partition.Partition()).Msg("Stopped consuming Kafka partition")
+}
+
+// retryDelay doubles with each consecutive failure, up to maxRetryDelay.
+func (w *partitionWorker) retryDelay() time.Duration {
+	if w.failures <= 1 {
+		return time.Second
+	}
+	return min(time.Second<<min(w.failures-1, 5), maxRetryDelay)
+}
+
+// processBatch stores the pending batch, or a newly fetched one, and commits it. A batch stays pending
+// while a store fails as a whole, however long that lasts. Entries a store rejects are tried again and,
+// after maxAttempts attempts, moved to the dead-letter topic, unless there is none.
+func (s *logConsumerService) processBatch(ctx context.Context, worker *partitionWorker) error {
+	if worker.pending == nil {
+		batch, err := s.fetchBatch(ctx, worker.consumer)
 		if err != nil {
-			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-				log.Info().Msg("Context cancelled during batch processing.")
-				return
+			return err
+		}
+		if len(batch.messages) == 0 {
+			log.Debug().Msg("No messages in batch to process.")
+			return nil // Nothing to do
+		}
+		if worker.committed < 0 {
+			// Everything before the first message fetched was committed by an earlier owner
+			worker.committed = batch.messages[0].Offset
+		}
+		worker.highWaterMark = batch.messages[len(batch.messages)-1].HighWaterMark
+		worker.pending = batch
+	}
+	batch := worker.pending
+
+	if len(batch.undecodable) > 0 {
+		if s.deadLetters.Enabled() {
+			if err := s.deadLetters.Send(ctx, batch.undecodable); err != nil {
+				return fmt.Errorf("failed moving undecodable messages to the dead-letter topic: %w", err)
+			}
+			batch.deadLettered += len(batch.undecodable)
+		} else {
+			log.Warn().Int("count", len(batch.undecodable)).Msg("Skipping Kafka messages that are not log entries")
+			batch.skipped += len(batch.undecodable)
+		}
+		batch.undecodable = nil
+	}
+
+	if !batch.stored {
+		log.Debug().Int("batch_size", len(batch.messages)).Int("attempt", batch.attempts+1).Msg("Processing collected batch...")
+		if err := s.storeBatch(ctx, batch); err != nil {
+			// Stores that are down are waited for without counting attempts
+			return fmt.Errorf("failed storing batch: %w", err)
+		}
+		if len(batch.rejected) > 0 {
+			batch.attempts++
+			if !s.deadLetters.Enabled() || batch.attempts < s.maxAttempts {
+				return fmt.Errorf("attempt %d at storing batch: %d log entries rejected", batch.attempts, len(batch.rejected))
+			}
+			letters := make([]model.DeadLetter, 0, len(batch.rejected))
+			for i, msg := range batch.messages {
+				if cause, ok := batch.rejected[i]; ok {
+					letters = append(letters, newDeadLetter(msg, model.DeadLetterStageStore, cause, batch.attempts))
+				}
+			}
+			if err := s.deadLetters.Send(ctx, letters); err != nil {
+				return fmt.Errorf("failed moving rejected log entries to the dead-letter topic after %d attempts: %w", batch.attempts, err)
 			}
-			log.Error().Err(err).Msg("Error processing consumer batch")
-			time.Sleep(1 * time.Second)
+			batch.deadLettered += len(letters)
 		}
+		batch.storedCount = len(batch.messages) - batch.deadLettered - batch.skipped
+		batch.stored = true
+	}
+
+	// Commit to Kafka only once the batch is stored or dead-lettered
+	log.Debug().Int("message_count", len(batch.messages)).Msg("Attempting to commit Kafka messages...")
+	if err := worker.consumer.CommitMessages(ctx, batch.messages...); err != nil {
+		log.Error().Err(err).Msg("Failed to commit Kafka messages after successful storage")
+		// The batch stays pending with only the commit left
+		return fmt.Errorf("failed committing kafka messages: %w", err)
 	}
+	log.Info().Int("partition", worker.consumer.Partition()).Int("batch_size", len(batch.messages)).Msg("Successfully processed and committed batch.")
+	worker.committed = batch.messages[len(batch.messages)-1].Offset + 1
+	s.metrics.ConsumerMessages.WithLabelValues("stored").Add(float64(batch.storedCount))
+	s.metrics.ConsumerMessages.WithLabelValues("dead_lettered").Add(float64(batch.deadLettered))
+	s.metrics.ConsumerMessages.WithLabelValues("skipped").Add(float64(batch.skipped))
+	worker.pending = nil
+	if err := s.miner.Save(); err != nil {
+		log.Warn().Err(err).Msg("Failed to save learned templates, will retry after next batch")
+	}
+	return nil // Batch processed successfully
+}
+
+// consumerBatch is a batch of fetched messages, kept until it is stored or dead-lettered and committed.
+type consumerBatch struct {
+	messages    []kafkaGo.Message
+	entries     []*model.LogEntry  // Indexed like messages, nil for messages that are not log entries
+	stages      []int              // Indexed like messages, how many store stages each entry went through
+	rejected    map[int]error      // Entries a store rejected at the last attempt, by index in messages
+	undecodable []model.DeadLetter // Messages that are not log entries and are not dead-lettered yet
+	attempts    int                // Attempts at storing the batch in which entries were rejected
+	stored      bool               // Only the commit is left
+
+	// What became of the messages, counted once committed
+	storedCount  int
+	deadLettered int
+	skipped      int
 }
 
-func (s *logConsumerService) processBatch(ctx context.Context) error {
-	logEntries := make([]*model.LogEntry, 0, s.batchSize)
-	originalMessages := make([]kafkaGo.Message, 0, s.batchSize)
+func (s *logConsumerService) fetchBatch(ctx context.Context, consumer kafka.PartitionConsumer) (*consumerBatch, error) {
+	batch := &consumerBatch{
+		messages: make([]kafkaGo.Message, 0, s.batchSize),
+		entries:  make([]*model.LogEntry, 0, s.batchSize),
+		stages:   make([]int, 0, s.batchSize),
+	}
 	batchStartTime := time.Now()
-	commitNeeded := false
 
-	for len(logEntries) < s.batchSize {
+	for len(batch.messages) < s.batchSize {
 		select {
 		case <-ctx.Done():
 			log.Info().Msg("Context cancelled while building consumer batch.")
-			return ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
 		fetchCtx, cancel := context.WithTimeout(ctx, s.maxWaitTime-time.Since(batchStartTime))
 
-		logEntry, originalMsg, err := s.consumer.FetchMessage(fetchCtx)
+		logEntry, originalMsg, err := consumer.FetchMessage(fetchCtx)
 		cancel() // Cancel the fetch context
 
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
 				// Waited long enough, process whatever we have collected
-				log.Debug().Int("batch_size", len(logEntries)).Msg("Max wait time reached for batch, processing partial batch.")
+				log.Debug().Int("batch_size", len(batch.messages)).Msg("Max wait time reached for batch, processing partial batch.")
 				break
 			}
-			// If FetchMessage returns the original message even on unmarshal error, we still need to process it (to commit)
-			if originalMsg.Topic != "" { // Check if we got a message back despite error
-				originalMessages = append(originalMessages, originalMsg)
-				logWarn := log.Warn().Int64("offset", originalMsg.Offset)
-				if logEntry == nil {
-					logWarn.Msg("Adding message with unmarshal error to batch for commit tracking.")
-				}
-				// Add nil entry if parsing failed, but track the message
-				logEntries = append(logEntries, logEntry) // logEntry might be nil here
-				continue                                  // Continue fetching next message
+			// FetchMessage returns the original message when it is not a log entry, so it can be dead-lettered and committed
+			if originalMsg.Topic != "" {
+				log.Warn().Int64("offset", originalMsg.Offset).Msg("Adding message with unmarshal error to batch for commit tracking.")
+				batch.messages = append(batch.messages, originalMsg)
+				batch.entries = append(batch.entries, nil)
+				batch.stages = append(batch.stages, 0)
+				batch.undecodable = append(batch.undecodable, newDeadLetter(originalMsg, model.DeadLetterStageDecode, err, 1))
+				continue // Continue fetching next message
 			}
 
-			log.Error().Err(err).Msg("Failed to fetch message, stopping batch accumulation for now.")
-			// Decide: return error immediately? Or try processing what we have?
-			// Returning error to retry fetch on next cycle.
-			return fmt.Errorf("failed to fetch kafka message: %w", err)
+			if len(batch.messages) > 0 {
+				// Messages already fetched are not returned again, so process them and fetch again on the next cycle
+				log.Error().Err(err).Int("batch_size", len(batch.messages)).Msg("Failed to fetch message, processing partial batch.")
+				break
+			}
+			return nil, fmt.Errorf("failed to fetch kafka message: %w", err)
 		}
 
 		// Successfully fetched and parsed
-		logEntries = append(logEntries, logEntry)
-		originalMessages = append(originalMessages, originalMsg)
-		commitNeeded = true // We got at least one valid message
+		batch.messages = append(batch.messages, originalMsg)
+		batch.entries = append(batch.entries, logEntry)
+		batch.stages = append(batch.stages, 0)
+	}
+	return batch, nil
+}
+
+// newDeadLetter describes a message the consumer gives up on.
+func newDeadLetter(msg kafkaGo.Message, stage string, cause error, attempts int) model.DeadLetter {
+	return model.DeadLetter{
+		Key:               string(msg.Key),
+		Value:             string(msg.Value),
+		Stage:             stage,
+		Error:             cause.Error(),
+		Attempts:          attempts,
+		FailedAt:          time.Now().UTC(),
+		OriginalTopic:     msg.Topic,
+		OriginalPartition: msg.Partition,
+		OriginalOffset:    msg.Offset,
+	}
+}
 
-		// If we hit batch size limit exactly, break loop
-		if len(logEntries) >= s.batchSize {
-			break
+// storeStage writes what one store keeps of log entries. Storing entries again must not duplicate
+// them: Elasticsearch documents and metric events are keyed by entry ID, and issues count an
+// occurrence once per entry ID.
+type storeStage struct {
+	name  string
+	store func(ctx context.Context, entries []*model.LogEntry) error
+}
+
+// storeBatch takes the batch's entries through the store stages they did not go through yet, so a
+// batch tried again does not store anything twice. Entries a stage rejects stop there, in
+// batch.rejected, while the others go on. When a store fails as a whole, the error is returned.
+func (s *logConsumerService) storeBatch(ctx context.Context, batch *consumerBatch) error {
+	// Entries that matched no known template get one learned online
+	for _, entry := range batch.entries {
+		if entry != nil && entry.EventID == "" && entry.Component != "ORPHAN" {
+			if eventID, params, ok := s.miner.Learn(entry.Content); ok {
+				entry.EventID = eventID
+				entry.EventParams = params
+			}
 		}
 	}
 
-	// --- Process the collected batch ---
-	if len(logEntries) == 0 {
-		log.Debug().Msg("No messages in batch to process.")
-		return nil // Nothing to do
+	batch.rejected = make(map[int]error)
+	for n, stage := range s.stages {
+		var indices []int
+		var entries []*model.LogEntry
+		for i, entry := range batch.entries {
+			if entry != nil && batch.stages[i] == n {
+				indices = append(indices, i)
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		rejected, err := s.runStage(ctx, stage, entries)
+		if err != nil {
+			log.Error().Err(err).Str("stage", stage.name).Msg("Failed to store consumer batch")
+			return fmt.Errorf("failed storing %s: %w", stage.name, err)
+		}
+		for j, i := range indices {
+			if cause, ok := rejected[j]; ok {
+				batch.rejected[i] = fmt.Errorf("failed storing %s: %w", stage.name, cause)
+				continue
+			}
+			batch.stages[i]++
+		}
+		if len(rejected) > 0 {
+			log.Warn().Int("count", len(rejected)).Str("stage", stage.name).Msg("Store rejected log entries")
+		}
 	}
+	return nil
+}
 
-	log.Debug().Int("batch_size", len(logEntries)).Msg("Processing collected batch...")
+// runStage stores the entries through one stage and returns those rejected, by index in entries.
+// TimescaleDB does not tell whose row it refused, so the entries are then stored one by one.
+func (s *logConsumerService) runStage(ctx context.Context, stage storeStage, entries []*model.LogEntry) (map[int]error, error) {
+	err := stage.store(ctx, entries)
+	var rejected *elasticsearch.RejectedError
+	switch {
+	case err == nil:
+		return nil, nil
+	case errors.As(err, &rejected):
+		return rejected.Items, nil
+	case !timescaledb.IsDataError(err):
+		return nil, err
+	case len(entries) == 1:
+		return map[int]error{0: err}, nil
+	}
 
-	// 1. Extract Metrics
-	allMetricEvents := make([]model.MetricEvent, 0)
-	validLogEntries := make([]model.LogEntry, 0, len(logEntries))
-	for _, entry := range logEntries {
-		if entry != nil {
-			validLogEntries = append(validLogEntries, *entry)
-			events := s.extractor.ExtractMetricEvents(entry) // Gọi extractor
-			if len(events) > 0 {
-				allMetricEvents = append(allMetricEvents, events...)
+	rejectedRows := make(map[int]error)
+	for i := range entries {
+		if err := stage.store(ctx, entries[i:i+1]); err != nil {
+			if !timescaledb.IsDataError(err) {
+				return nil, err
 			}
+			rejectedRows[i] = err
 		}
 	}
+	return rejectedRows, nil
+}
 
-	// 2. Store Logs (Elasticsearch) - Filter out nil entries before sending
-	errLogStore := s.logStore.StoreLogs(ctx, validLogEntries)
-	if errLogStore != nil {
-		log.Error().Err(errLogStore).Msg("Failed to store logs to Elasticsearch")
-		// Decide strategy: Don't commit? Retry? Dead-letter queue?
-		// For now: Log error, DO NOT commit, leads to reprocessing.
-		return fmt.Errorf("failed storing logs: %w", errLogStore)
+func (s *logConsumerService) storeLogs(ctx context.Context, entries []*model.LogEntry) error {
+	logEntries := make([]model.LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		logEntries = append(logEntries, *entry)
 	}
+	return s.logStore.StoreLogs(ctx, logEntries)
+}
 
-	// 3. Store Metrics (TimescaleDB)
-	errMetricStore := s.metricStore.StoreMetricEvents(ctx, allMetricEvents)
-	if errMetricStore != nil {
-		log.Error().Err(errMetricStore).Msg("Failed to store metric events to TimescaleDB")
-		return fmt.Errorf("failed storing metrics: %w", errMetricStore)
+func (s *logConsumerService) storeMetrics(ctx context.Context, entries []*model.LogEntry) error {
+	allMetricEvents := make([]model.MetricEvent, 0)
+	for _, entry := range entries {
+		allMetricEvents = append(allMetricEvents, s.extractor.ExtractMetricEvents(entry)...)
 	}
-	// 4. Commit to Kafka (only if both stores succeeded)
-	if commitNeeded && errLogStore == nil {
-		log.Debug().Int("message_count", len(originalMessages)).Msg("Attempting to commit Kafka messages...")
-		errCommit := s.consumer.CommitMessages(ctx, originalMessages...)
-		if errCommit != nil {
-			log.Error().Err(errCommit).Msg("Failed to commit Kafka messages after successful storage")
-			// This is tricky. Data is stored, but offset isn't committed. Will reprocess on restart.
-			// Potentially implement retry logic for commits.
-			return fmt.Errorf("failed committing kafka messages: %w", errCommit)
+	return s.metricStore.StoreMetricEvents(ctx, allMetricEvents)
+}
+
+func (s *logConsumerService) storeIssues(ctx context.Context, entries []*model.LogEntry) error {
+	issues := make(map[string]*model.Issue)
+	for _, entry := range entries {
+		for _, event := range s.extractor.ExtractMetricEvents(entry) {
+			if event.MetricName == "error_event" {
+				addIssueOccurrence(issues, entry, event)
+			}
 		}
-		log.Info().Int("batch_size", len(logEntries)).Msg("Successfully processed and committed batch.")
-	} else if !commitNeeded {
-		log.Debug().Msg("No valid messages processed, skipping commit.")
-	} else {
-		log.Warn().Msg("Skipping Kafka commit due to storage errors.")
-		// Return the first error encountered
-		if errLogStore != nil {
-			return fmt.Errorf("failed storing logs: %w", errLogStore)
+	}
+	issueList := make([]model.Issue, 0, len(issues))
+	for _, issue := range issues {
+		issueList = append(issueList, *issue)
+	}
+	return s.issueStore.UpsertIssues(ctx, issueList)
+}
+
+func (s *logConsumerService) storeApplications(ctx context.Context, entries []*model.LogEntry) error {
+	appEvents := make([]model.ApplicationEvent, 0)
+	containerSpans := make(map[string]*containerSpan)
+	applicationSpans := make(map[string]*containerSpan)
+	for _, entry := range entries {
+		if appEvent, ok := parser.ParseApplicationEvent(entry); ok {
+			appEvents = append(appEvents, appEvent)
 		}
+		addContainerSighting(containerSpans, entry)
+		addApplicationSighting(applicationSpans, entry)
 	}
+	for _, span := range containerSpans {
+		appEvents = append(appEvents, span.events()...)
+	}
+	for _, span := range applicationSpans {
+		appEvents = append(appEvents, span.events()...)
+	}
+	return s.appStore.ApplyEvents(ctx, appEvents)
+}
 
-	return nil // Batch processed successfully
+// addIssueOccurrence folds one error event into the batch's issue with the same error_key.
+func addIssueOccurrence(issues map[string]*model.Issue, entry *model.LogEntry, event model.MetricEvent) {
+	key := event.Tags["error_key"]
+	issue, ok := issues[key]
+	if !ok {
+		issue = &model.Issue{
+			Fingerprint:    key,
+			Title:          issueTitle(entry),
+			ExceptionClass: event.Tags["exception_class"],
+			Component:      entry.Component,
+			Level:          entry.Level,
+			EventID:        entry.EventID,
+			FirstSeen:      event.Time,
+			LastSeen:       event.Time,
+			SampleRawLog:   entry.Raw,
+		}
+		issues[key] = issue
+	}
+	issue.Count++
+	if entry.ID != "" {
+		issue.OccurrenceIDs = append(issue.OccurrenceIDs, entry.ID)
+	}
+	if event.Time.Before(issue.FirstSeen) {
+		issue.FirstSeen = event.Time
+	}
+	if event.Time.After(issue.LastSeen) {
+		issue.LastSeen = event.Time
+		issue.SampleRawLog = entry.Raw
+	}
+	if !slices.Contains(issue.Applications, entry.Application) {
+		issue.Applications = append(issue.Applications, entry.Application)
+	}
+}
+
+// issueTitle is "<exception class>: <message>" for stack traces, else the first line of content.
+func issueTitle(entry *model.LogEntry) string {
+	title, _, _ := strings.Cut(entry.Content, "\n")
+	if entry.StackTrace != nil && len(entry.StackTrace.Exceptions) > 0 {
+		exception := entry.StackTrace.Exceptions[0]
+		title = exception.Class
+		if message, _, _ := strings.Cut(exception.Message, "\n"); message != "" {
+			title += ": " + message
+		}
+	}
+	if runes := []rune(title); len(runes) > maxIssueTitleLength {
+		title = string(runes[:maxIssueTitleLength])
+	}
+	return title
+}
+
+// containerSpan is the time range of the log lines one container wrote within a batch, or of the
+// lines of one application when containerID is empty.
+type containerSpan struct {
+	application string
+	containerID string
+	attempt     int
+	first, last time.Time
+}
+
+// addContainerSighting records that a container was alive at the time of an entry from its own log file,
+// so containers show up in their application's lifecycle even without YarnAllocator logs.
+func addContainerSighting(spans map[string]*containerSpan, entry *model.LogEntry) {
+	if entry.ContainerID == "" || entry.Application == "" || entry.Application == "unknown_application" {
+		return
+	}
+	span, ok := spans[entry.ContainerID]
+	if !ok {
+		spans[entry.ContainerID] = &containerSpan{application: entry.Application, containerID: entry.ContainerID, attempt: entry.Attempt, first: entry.Timestamp, last: entry.Timestamp}
+		return
+	}
+	span.add(entry.Timestamp)
+}
+
+// addApplicationSighting records that an application was logging at the time of an entry, so
+// applications without lifecycle events or container logs, e.g. ingested over HTTP or syslog, are listed too.
+func addApplicationSighting(spans map[string]*containerSpan, entry *model.LogEntry) {
+	if entry.Application == "" || entry.Application == "unknown_application" {
+		return
+	}
+	span, ok := spans[entry.Application]
+	if !ok {
+		spans[entry.Application] = &containerSpan{application: entry.Application, first: entry.Timestamp, last: entry.Timestamp}
+		return
+	}
+	span.add(entry.Timestamp)
+}
+
+// add widens the span to include t.
+func (span *containerSpan) add(t time.Time) {
+	if t.Before(span.first) {
+		span.first = t
+	}
+	if t.After(span.last) {
+		span.last = t
+	}
+}
+
+func (span *containerSpan) events() []model.ApplicationEvent {
+	kind := model.AppEventContainerSeen
+	if span.containerID == "" {
+		kind = model.AppEventSeen
+	}
+	seen := model.ApplicationEvent{
+		Kind:        kind,
+		Application: span.application,
+		Time:        span.first,
+		Attempt:     span.attempt,
+		ContainerID: span.containerID,
+	}
+	if span.last.Equal(span.first) {
+		return []model.ApplicationEvent{seen}
+	}
+	last := seen
+	last.Time = span.last
+	return []model.ApplicationEvent{seen, last}
 }
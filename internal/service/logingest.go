@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/filestate"
+	"skeleton-internship-backend/internal/parser"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrIngestBusy is returned when IngestMaxInFlight requests are already being processed.
+	ErrIngestBusy = errors.New("too many ingest requests in flight")
+	// ErrIngestUnavailable is returned when Kafka did not acknowledge every entry within IngestTimeout.
+	ErrIngestUnavailable = errors.New("log delivery to kafka failed")
+)
+
+// Ingest parses body like a log file of req.Application and returns once Kafka has acknowledged
+// every entry. Entry IDs derive from req.RequestID, so retrying a failed request with the same ID
+// overwrites the entries that did get through instead of duplicating them.
+func (s *logProducerService) Ingest(ctx context.Context, req dto.IngestRequest, body io.Reader) (dto.IngestResponse, error) {
+	if strings.TrimSpace(req.Application) == "" {
+		return dto.IngestResponse{}, fmt.Errorf("invalid ingest request: application is required")
+	}
+	if req.RequestID == "" {
+		req.RequestID = uuid.NewString()
+	}
+	if req.Source == "" {
+		req.Source = "http"
+	}
+	resp := dto.IngestResponse{RequestID: req.RequestID}
+
+	select {
+	case s.ingestSlots <- struct{}{}:
+		defer func() { <-s.ingestSlots }()
+	default:
+		return resp, ErrIngestBusy
+	}
+
+	// Not a real file: the path only feeds SourceFile and the format and timezone rules
+	streamPath := path.Join("ingest", req.Application, req.Source)
+	buffered := bufio.NewReaderSize(body, sniffSize)
+	var logParser parser.LogParser
+	if req.Format != "" {
+		var ok bool
+		if logParser, ok = s.parsers.Get(req.Format); !ok {
+			return resp, fmt.Errorf("invalid ingest request: unknown log format %q", req.Format)
+		}
+	} else {
+		logParser = s.sniffParser(streamPath, buffered)
+	}
+	meta := parser.PathMetadata{Application: req.Application, ContainerID: req.ContainerID}
+	if req.ContainerID != "" {
+		meta.Attempt = parser.ContainerAttempt(req.ContainerID)
+	}
+
+	stream := logStream{
+		key:     "ingest:" + req.RequestID,
+		path:    streamPath,
+		reader:  buffered,
+		modTime: time.Now(),
+		parser:  logParser,
+		meta:    &meta,
+	}
+	linesRead, _, entries, err := s.readEntries(ctx, stream, filestate.FileState{}, true)
+	resp.LinesRead = linesRead
+	resp.Entries = len(entries)
+	if err != nil {
+		return resp, fmt.Errorf("failed to read ingest request: %w", err)
+	}
+
+	produceCtx, cancel := context.WithTimeout(ctx, s.ingestTimeout)
+	defer cancel()
+	batchSize := max(s.cfg.BatchSize, 1)
+	for start := 0; start < len(entries); start += batchSize {
+		delivered, err := s.sendBatch(produceCtx, entries[start:min(start+batchSize, len(entries))])
+		for _, ok := range delivered {
+			if ok {
+				resp.Delivered++
+			}
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("request_id", req.RequestID).Str("application", req.Application).Int("delivered", resp.Delivered).Int("entries", resp.Entries).Msg("Failed to deliver ingested logs")
+			return resp, fmt.Errorf("%w: %w", ErrIngestUnavailable, err)
+		}
+	}
+
+	log.Debug().Str("request_id", req.RequestID).Str("application", req.Application).Int64("lines_read", linesRead).Int("entries", resp.Entries).Msg("Ingested logs")
+	return resp, nil
+}
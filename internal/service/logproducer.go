@@ -3,14 +3,20 @@ package service
 import (
 	"bufio"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/dto"
 	"skeleton-internship-backend/internal/filestate"
 	"skeleton-internship-backend/internal/kafka"
 	"skeleton-internship-backend/internal/model"
 	"skeleton-internship-backend/internal/parser"
+	"skeleton-internship-backend/internal/telemetry"
 	"strings"
 	"sync"
 	"time"
@@ -19,31 +25,66 @@ import (
 )
 
 type LogProducerService interface {
+	// ProcessLogs reads everything appended to the log files since the last run.
 	ProcessLogs(ctx context.Context) error
+	// Tail watches the log directory and processes files as soon as they change, until ctx is cancelled.
+	Tail(ctx context.Context, wg *sync.WaitGroup)
+	// Stats reports throughput of the last run and how far behind the log files the producer is.
+	Stats() dto.ProducerStatsResponse
+	// Ingest reads log lines pushed over HTTP instead of written to a file and sends them to Kafka.
+	Ingest(ctx context.Context, req dto.IngestRequest, body io.Reader) (dto.IngestResponse, error)
 }
 
 type logProducerService struct {
-	parser      parser.LogParser
-	producer    kafka.LogProducer
-	kafkaCfg    *config.KafkaConfig
-	cfg         *config.LogProcessorConfig
-	stateMgr    filestate.Manager
-	processLock sync.Mutex
+	parsers       *parser.Registry
+	timestamps    *parser.TimestampResolver
+	templates     parser.TemplateMatcher
+	fields        parser.FieldExtractor
+	producer      kafka.LogProducer
+	kafkaCfg      *config.KafkaConfig
+	cfg           *config.LogProcessorConfig
+	stateMgr      filestate.Manager
+	sources       []*logSource
+	ingestSlots   chan struct{}
+	ingestTimeout time.Duration
+	processLock   sync.Mutex
+	stats         producerStats
+	metrics       *telemetry.Metrics
 }
 
 func NewLogProducerService(
 	cfg *config.Config,
 	stateMgr filestate.Manager,
-	parser parser.LogParser,
+	parsers *parser.Registry,
+	timestamps *parser.TimestampResolver,
+	templates parser.TemplateMatcher,
+	fields parser.FieldExtractor,
 	producer kafka.LogProducer,
-) LogProducerService {
-	return &logProducerService{
-		cfg:      &cfg.LogProcessor,
-		kafkaCfg: &cfg.Kafka,
-		stateMgr: stateMgr,
-		parser:   parser,
-		producer: producer,
+	metrics *telemetry.Metrics,
+) (LogProducerService, error) {
+	sources, err := newLogSources(cfg.LogProcessor.Sources)
+	if err != nil {
+		return nil, err
+	}
+	ingestTimeout := cfg.LogProcessor.IngestTimeout
+	if ingestTimeout <= 0 {
+		log.Warn().Dur("ingest_timeout", ingestTimeout).Msg("LOG_PROCESSOR_INGEST_TIMEOUT must be positive, using 10s")
+		ingestTimeout = 10 * time.Second
 	}
+	return &logProducerService{
+		cfg:           &cfg.LogProcessor,
+		kafkaCfg:      &cfg.Kafka,
+		stateMgr:      stateMgr,
+		sources:       sources,
+		ingestSlots:   make(chan struct{}, max(cfg.LogProcessor.IngestMaxInFlight, 1)),
+		ingestTimeout: ingestTimeout,
+		parsers:       parsers,
+		timestamps:    timestamps,
+		templates:     templates,
+		fields:        fields,
+		producer:      producer,
+		metrics:       metrics,
+	}, nil
 }
 func (s *logProducerService) ProcessLogs(ctx context.Context) error {
 	if !s.processLock.TryLock() {
@@ -53,6 +94,22 @@ func (s *logProducerService) ProcessLogs(ctx context.Context) error {
 	defer s.processLock.Unlock()
 
 	log.Info().Msg("Starting log processing cycle...")
+	logFiles, complete, err := s.findLogFiles()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find log files")
+		return fmt.Errorf("failed to find log files: %w", err)
+	}
+	log.Debug().Int("file_count", len(logFiles)).Msg("Found log files to process")
+	// The state of files under a source that failed to scan is kept
+	return s.processFiles(ctx, logFiles, complete)
+}
+
+// processFiles reads the given files from their saved offsets and sends the entries to Kafka.
+// Files are read concurrently by up to Workers goroutines; the entries of each file stay in order and
+// only this goroutine sends them and merges the resulting offsets into the state. When allFiles is
+// set, logFiles are every discovered file and the state of any other file is dropped.
+// Callers must hold processLock.
+func (s *logProducerService) processFiles(ctx context.Context, logFiles []string, allFiles bool) error {
 	startTime := time.Now()
 
 	currentState, err := s.stateMgr.LoadState()
@@ -69,61 +126,137 @@ func (s *logProducerService) ProcessLogs(ctx context.Context) error {
 	for k, v := range currentState {
 		newState[k] = v
 	}
-	logFiles, err := s.findLogFiles()
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to find log files")
-		return fmt.Errorf("failed to find log files: %w", err)
-	}
-	log.Debug().Int("file_count", len(logFiles)).Msg("Found log files to process")
+	batchSize := max(s.cfg.BatchSize, 1)
 	var totalLinesRead int64
+	var totalBytesRead int64
 	var totalEntriesSent int64
-	var allLogs []model.LogEntry
+	var totalEntriesFailed int64
+	var batch []pendingEntry
+	progress := make(map[string]*fileProgress)
+	unsaved := false // newState changed without anything to deliver
+
+	// send delivers the batch, at most BatchSize entries per request, and moves each file's offset past
+	// the entries Kafka acknowledged. The state is saved after every request so a crash after delivery
+	// does not resend them. Unless flushAll is set, fewer than BatchSize entries are kept for later.
+	send := func(flushAll bool) error {
+		for len(batch) >= batchSize || (flushAll && len(batch) > 0) {
+			chunk := batch[:min(batchSize, len(batch))]
+			batch = batch[len(chunk):]
+
+			delivered, err := s.sendBatch(ctx, chunk)
+			for i, pending := range chunk {
+				p := progress[pending.key]
+				if !delivered[i] {
+					totalEntriesFailed++
+				}
+				if p.failed {
+					continue
+				}
+				if !delivered[i] {
+					// Later entries of this file must wait for this one, even if they were delivered
+					p.failed = true
+					continue
+				}
+				totalEntriesSent++
+				newState[pending.key] = pending.commit
+				if p.remaining--; p.remaining == 0 {
+					newState[pending.key] = p.final
+				}
+			}
+			if errSave := s.stateMgr.SaveState(newState); errSave != nil {
+				log.Error().Err(errSave).Msg("Failed to save file state after delivery")
+				if err == nil {
+					err = fmt.Errorf("failed to save file state: %w", errSave)
+				}
+			} else {
+				unsaved = false
+			}
+			if err != nil {
+				batch = nil
+				return err
+			}
+		}
+		return nil
+	}
 
-	for _, filePath := range logFiles {
-		processedCount, newOffset, fileLogs, err := s.processSingleFileMultiline(ctx, filePath, newState)
-		if err != nil {
-			log.Error().Err(err).Str("file", filePath).Msg("Failed to process file")
-			newState[filePath] = currentState[filePath]
+	readCtx, stopReading := context.WithCancel(ctx)
+	defer stopReading()
+	var sendErr error
+	// Every result is received, even after a failed send, so that no worker is left blocked
+	for result := range s.readFiles(readCtx, logFiles, currentState) {
+		if result.err != nil {
+			log.Error().Err(result.err).Str("file", result.path).Msg("Failed to process file")
+			continue
+		}
+		if sendErr != nil {
 			continue
 		}
 
-		newState[filePath] = newOffset
-		if processedCount > 0 {
-			log.Debug().Str("file", filePath).Int64("lines_read", processedCount).Int("entries_found", len(fileLogs)).Msg("Processed file")
-			totalLinesRead += processedCount
-			allLogs = append(allLogs, fileLogs...)
-
-			if len(allLogs) >= s.cfg.BatchSize {
-				batchToSend := make([]model.LogEntry, len(allLogs))
-				copy(batchToSend, allLogs)
-				allLogs = []model.LogEntry{}
-
-				err := s.sendBatch(ctx, batchToSend) // Gửi batch
-				if err != nil {
-					log.Error().Err(err).Msg("Failed to send intermediate batch to Kafka")
-					// Tạm thời log lỗi và bỏ qua batch này (có thể mất log)
-				} else {
-					totalEntriesSent += int64(len(batchToSend))
-				}
+		for _, read := range result.reads {
+			totalBytesRead += read.bytesRead
+			if read.renamedFrom != "" {
+				// The file's state moves to its new path, so the old path cannot be matched again
+				delete(newState, read.renamedFrom)
+				s.parsers.Forget(read.renamedFrom)
+				newState[read.key] = read.resumed
+				unsaved = true
+			}
+			if len(read.entries) == 0 {
+				// Nothing to deliver, only the identity or trailing lines changed
+				newState[read.key] = read.final
+				unsaved = true
+				continue
 			}
+			log.Debug().Str("file", read.key).Int64("lines_read", read.linesRead).Int("entries_found", len(read.entries)).Msg("Processed file")
+			totalLinesRead += read.linesRead
+			progress[read.key] = &fileProgress{remaining: len(read.entries), final: read.final}
+			batch = append(batch, read.entries...)
 		}
-	}
 
-	if len(allLogs) > 0 {
-		err := s.sendBatch(ctx, allLogs)
-		if err != nil {
-			log.Error().Err(err).Msg("Failed to send final batch to Kafka")
-		} else {
-			totalEntriesSent += int64(len(allLogs))
+		if sendErr = send(false); sendErr != nil {
+			// The broker is most likely unavailable; leave the remaining files for the next run
+			log.Error().Err(sendErr).Msg("Failed to send intermediate batch to Kafka, stopping this run")
+			stopReading()
 		}
 	}
 
-	if err := s.stateMgr.SaveState(newState); err != nil {
-		log.Error().Err(err).Msg("Failed to save final file state")
-		return fmt.Errorf("failed to save final file state: %w", err)
+	if sendErr == nil {
+		if sendErr = send(true); sendErr != nil {
+			log.Error().Err(sendErr).Msg("Failed to send final batch to Kafka")
+		}
+	}
+	if allFiles && sendErr == nil {
+		if pruned := pruneFileState(newState, logFiles); len(pruned) > 0 {
+			log.Info().Int("pruned", len(pruned)).Msg("Dropped the state of log files that are gone")
+			s.parsers.Forget(pruned...)
+			unsaved = true
+		}
+	}
+	if unsaved {
+		if err := s.stateMgr.SaveState(newState); err != nil {
+			log.Error().Err(err).Msg("Failed to save file state")
+			if sendErr == nil {
+				sendErr = fmt.Errorf("failed to save file state: %w", err)
+			}
+		}
 	}
 
 	duration := time.Since(startTime)
+	s.stats.recordRun(runStats{
+		files:         len(logFiles),
+		linesRead:     totalLinesRead,
+		entriesSent:   totalEntriesSent,
+		entriesFailed: totalEntriesFailed,
+		duration:      duration,
+		failed:        sendErr != nil,
+	})
+	s.stats.recordLag(logFiles, newState)
+	s.metrics.ProducerCycleDuration.Observe(duration.Seconds())
+	s.metrics.ProducerCycleFiles.Observe(float64(len(logFiles)))
+	s.metrics.ProducerCycleLines.Observe(float64(totalLinesRead))
+	s.metrics.ProducerCycleBytes.Observe(float64(totalBytesRead))
+	s.metrics.ProducerEntries.WithLabelValues("sent").Add(float64(totalEntriesSent))
+	s.metrics.ProducerEntries.WithLabelValues("failed").Add(float64(totalEntriesFailed))
 	log.Info().
 		Int64("lines_read", totalLinesRead).
 		Int64("entries_sent", totalEntriesSent).
@@ -131,72 +264,291 @@ func (s *logProducerService) ProcessLogs(ctx context.Context) error {
 		Dur("duration", duration).
 		Msg("Finished log processing cycle.")
 
-	return nil
+	return sendErr
 }
 
-func (s *logProducerService) findLogFiles() ([]string, error) {
-	var logFiles []string
-	appDirs, err := os.ReadDir(s.cfg.LogDirectory)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load log directory: %w", err)
-	}
-	for _, appDir := range appDirs {
-		if appDir.IsDir() && strings.HasPrefix(appDir.Name(), "application") {
-			appDirPath := filepath.Join(s.cfg.LogDirectory, appDir.Name())
-			containerFiles, err := os.ReadDir(appDirPath)
-			if err != nil {
-				log.Warn().Err(err).Str("dir", appDirPath).Msg("Failed to read application directory")
-				continue
+// fileResult is what a worker read from one of the files given to processFiles. Archives are
+// delivered member by member, in one result each.
+type fileResult struct {
+	path  string
+	reads []fileRead
+	err   error
+}
+
+// readFiles reads logFiles on up to Workers goroutines against a snapshot of the file state, which
+// they must not modify. The returned channel is closed once every started read is delivered; cancelling
+// ctx stops handing out files.
+func (s *logProducerService) readFiles(ctx context.Context, logFiles []string, state filestate.FileProcessState) <-chan fileResult {
+	workers := min(max(s.cfg.Workers, 1), max(len(logFiles), 1))
+	paths := make(chan string)
+	// A small buffer lets workers read ahead while a batch is being sent, without holding many files in memory
+	results := make(chan fileResult, workers)
+
+	go func() {
+		defer close(paths)
+		for _, filePath := range logFiles {
+			select {
+			case paths <- filePath:
+			case <-ctx.Done():
+				return
 			}
-			for _, containerFile := range containerFiles {
-				if !containerFile.IsDir() && strings.HasSuffix(containerFile.Name(), ".log") {
-					logFiles = append(logFiles, filepath.Join(appDirPath, containerFile.Name()))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filePath := range paths {
+				err := s.readLogFile(ctx, filePath, state, func(read fileRead) {
+					results <- fileResult{path: filePath, reads: []fileRead{read}}
+				})
+				if err != nil {
+					results <- fileResult{path: filePath, err: err}
 				}
 			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// findLogFiles lists the log files of every source. A file under several roots is listed once.
+// It only fails if nothing could be listed; complete is false if some source could not be scanned.
+func (s *logProducerService) findLogFiles() (logFiles []string, complete bool, err error) {
+	var scanErr error
+	seen := make(map[string]bool)
+	for _, src := range s.sources {
+		files, _, err := src.scan(src.root)
+		if err != nil {
+			log.Warn().Err(err).Str("source", src.name).Str("root", src.root).Msg("Failed to scan log source")
+			scanErr = err
+			continue
+		}
+		for _, file := range files {
+			if !seen[file] {
+				seen[file] = true
+				logFiles = append(logFiles, file)
+			}
+		}
+	}
+	if len(logFiles) == 0 && scanErr != nil {
+		return nil, false, fmt.Errorf("failed to load log directory: %w", scanErr)
+	}
+	return logFiles, scanErr == nil, nil
+}
+
+func isApplicationDir(name string) bool {
+	return strings.HasPrefix(name, "application")
+}
+
+func isContainerLog(path string) bool {
+	return strings.HasSuffix(path, ".log")
+}
+
+// readLogFile reads what is new in a plain, compressed or archived log file and passes it to emit.
+// An archive is emitted one member at a time, so only one member's entries are held at once.
+func (s *logProducerService) readLogFile(ctx context.Context, filePath string, state filestate.FileProcessState, emit func(fileRead)) error {
+	switch {
+	case isArchive(filePath):
+		return s.processArchive(ctx, filePath, state, emit)
+	case isCompressedLog(filePath):
+		return s.processCompressedFile(ctx, filePath, state, emit)
+	default:
+		read, err := s.processSingleFileMultiline(ctx, filePath, state)
+		if err != nil {
+			return err
 		}
+		emit(read)
+		return nil
 	}
-	return logFiles, nil
 }
 
-func (s *logProducerService) processSingleFileMultiline(ctx context.Context, filePath string, state filestate.FileProcessState) (linesRead int64, newOffset int64, entries []model.LogEntry, err error) {
-	lastOffset := state[filePath]
+func (s *logProducerService) processSingleFileMultiline(ctx context.Context, filePath string, state filestate.FileProcessState) (fileRead, error) {
+	saved, tracked := state[filePath]
 
 	file, err := os.Open(filePath)
 	if err != nil {
-		return 0, lastOffset, nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+		return fileRead{}, fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
 	info, err := file.Stat()
 	if err != nil {
-		return 0, lastOffset, nil, fmt.Errorf("failed to stat file %s: %w", filePath, err)
+		return fileRead{}, fmt.Errorf("failed to stat file %s: %w", filePath, err)
+	}
+	identity, err := filestate.Identify(file, info)
+	if err != nil {
+		return fileRead{}, fmt.Errorf("failed to identify file %s: %w", filePath, err)
+	}
+	read := fileRead{key: filePath}
+
+	var offset int64
+	if !tracked {
+		if oldPath, previous, ok := state.FindByIdentity(filePath, file, info); ok {
+			log.Info().Str("file", filePath).Str("old_path", oldPath).Int64("offset", previous.Offset).Msg("Log file was renamed, resuming from its saved offset")
+			offset = previous.Offset
+			identity.LastTimestamp = previous.LastTimestamp
+			read.renamedFrom = oldPath
+			read.resumed = identity
+			read.resumed.Offset = offset
+		}
+	} else if same, err := saved.Matches(file, info); err != nil {
+		return fileRead{}, fmt.Errorf("failed to check identity of file %s: %w", filePath, err)
+	} else if same {
+		offset = saved.Offset
+		identity.LastTimestamp = saved.LastTimestamp
+		if info.Size() < offset {
+			log.Warn().Str("file", filePath).Int64("last_offset", offset).Int64("current_size", info.Size()).Msg("File truncated in place, resetting offset.")
+			offset = 0
+			identity.LastTimestamp = nil
+		}
+	} else {
+		// The file we were reading has been rotated away or truncated and rewritten.
+		// Whatever it received after our offset is only left in a rotated sibling.
+		read.linesRead, read.bytesRead, read.entries, err = s.drainRotated(ctx, filePath, saved)
+		if err != nil {
+			return fileRead{}, err
+		}
+		log.Info().Str("file", filePath).Int64("drained_lines", read.linesRead).Msg("Log file was rotated, reading the new file from the start")
 	}
-	currentSize := info.Size()
 
-	if currentSize < lastOffset {
-		log.Warn().Str("file", filePath).Int64("last_offset", lastOffset).Int64("current_size", currentSize).Msg("File truncated or rotated? Resetting offset.")
-		lastOffset = 0
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fileRead{}, fmt.Errorf("failed to seek file %s to offset %d: %w", filePath, offset, err)
+	}
+	identity.Offset = offset
+	// Nobody appends to a file that has been idle that long, so its trailing entry is complete
+	idle := time.Since(info.ModTime()) >= s.cfg.MultilineIdleTimeout
+	stream := logStream{key: filePath, path: filePath, reader: file, modTime: info.ModTime()}
+	fileLines, end, fileEntries, err := s.readEntries(ctx, stream, identity, idle)
+	if err != nil {
+		return fileRead{}, err
 	}
+	read.linesRead += fileLines
+	read.bytesRead += end.Offset - offset
+	read.entries = append(read.entries, fileEntries...)
+	read.final = end
+	return read, nil
+}
 
-	if _, err = file.Seek(lastOffset, 0); err != nil {
-		return 0, lastOffset, nil, fmt.Errorf("failed to seek file %s to offset %d: %w", filePath, lastOffset, err)
+// drainRotated reads the rest of the file previously tracked at filePath from a rotated sibling such as
+// filePath.1, if one is still around. Entries are attributed to filePath. It returns the lines and bytes read.
+func (s *logProducerService) drainRotated(ctx context.Context, filePath string, saved filestate.FileState) (int64, int64, []pendingEntry, error) {
+	siblings, err := filepath.Glob(filePath + ".*")
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to list rotated files of %s: %w", filePath, err)
 	}
+	for _, sibling := range siblings {
+		file, err := os.Open(sibling)
+		if err != nil {
+			log.Warn().Err(err).Str("file", sibling).Msg("Failed to open rotated log file")
+			continue
+		}
+		info, err := file.Stat()
+		var same bool
+		if err == nil {
+			// A copy left by copytruncate has a different inode but the same head
+			same, err = saved.HasHead(file, info)
+		}
+		if err != nil || !same || info.Size() < saved.Offset {
+			file.Close()
+			continue
+		}
+
+		log.Info().Str("file", filePath).Str("rotated_file", sibling).Int64("offset", saved.Offset).Msg("Draining rotated log file")
+		// Entries commit as offsets into the rotated file under the old identity, so an interrupted
+		// drain resumes from the last delivered entry
+		var linesRead int64
+		end := saved
+		var entries []pendingEntry
+		if _, err = file.Seek(saved.Offset, io.SeekStart); err == nil {
+			stream := logStream{key: filePath, path: filePath, reader: file, modTime: info.ModTime()}
+			linesRead, end, entries, err = s.readEntries(ctx, stream, saved, true)
+		}
+		file.Close()
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("failed to drain rotated file %s: %w", sibling, err)
+		}
+		return linesRead, end.Offset - saved.Offset, entries, nil
+	}
+	log.Warn().Str("file", filePath).Int64("offset", saved.Offset).Msg("Rotated file not found, lines written before rotation may be missing")
+	return 0, 0, nil, nil
+}
+
+// readEntries parses stream, which is positioned at position.Offset, to its end. Each entry carries
+// position advanced past it, to be committed once the entry is delivered, and so does end.
+//
+// The entry at the end of the file may still be growing (e.g. a stack trace being written). Unless
+// flushTrailing is set it is held back: end stops at its first line, so the next run reads it again in full.
+func (s *logProducerService) readEntries(ctx context.Context, stream logStream, position filestate.FileState, flushTrailing bool) (linesRead int64, end filestate.FileState, entries []pendingEntry, err error) {
+	filePath := stream.path
+	lastOffset := position.Offset
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(stream.reader)
 	currentOffset := lastOffset
+	var lineOffset int64  // Bytes the current line takes in the file, including its line ending
+	var lineComplete bool // Whether the current line ends with a newline
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			lineOffset = int64(advance)
+			lineComplete = advance > 0 && data[advance-1] == '\n'
+		}
+		return advance, token, err
+	})
 
 	var currentEntry *model.LogEntry  // Entry đang được xây dựng
 	var contentBuffer strings.Builder // Buffer cho content đa dòng
 	var rawBuffer strings.Builder     // Buffer cho raw log đa dòng
+	var entryStart int64              // Offset of currentEntry's header line
+
+	logParser := stream.parser
+	if logParser == nil {
+		logParser = s.parsers.ForFile(filePath)
+	}
+	var pathMeta parser.PathMetadata
+	if stream.meta != nil {
+		pathMeta = *stream.meta
+	} else {
+		pathMeta = s.pathMetadata(filePath)
+	}
+	location := s.timestamps.LocationFor(filePath)
+	// Lines without a usable timestamp inherit the previous entry's, possibly read in an earlier run;
+	// before the first entry of a new file, the file's mtime
+	previousTimestamp := stream.modTime.UTC()
+	if position.LastTimestamp != nil {
+		previousTimestamp = *position.LastTimestamp
+	}
 
-	appID := parser.ExtractApplicationID(filePath)
+	addEntry := func(entry model.LogEntry, start, end int64) {
+		entry.ID = entryID(stream.key, position, start, entry.Raw)
+		commit := position
+		commit.Offset = end
+		timestamp := entry.Timestamp
+		commit.LastTimestamp = &timestamp
+		entries = append(entries, pendingEntry{entry: entry, key: stream.key, commit: commit})
+	}
+	// endAt is the position following the last entry read, which ends at offset
+	endAt := func(offset int64) filestate.FileState {
+		end := position
+		end.Offset = offset
+		if len(entries) > 0 {
+			end.LastTimestamp = entries[len(entries)-1].commit.LastTimestamp
+		}
+		return end
+	}
 
 	// Hàm nội bộ để hoàn thiện và thêm entry vào kết quả
-	finalizeEntry := func() {
+	finalizeEntry := func(end int64) {
 		if currentEntry != nil {
 			currentEntry.Content = contentBuffer.String()
 			currentEntry.Raw = rawBuffer.String()
-			entries = append(entries, *currentEntry)
+			enrichEntry(currentEntry, s.templates, s.fields)
+			addEntry(*currentEntry, entryStart, end)
 			log.Trace().Str("file", filePath).Msg("Finalized log entry")
 		}
 		currentEntry = nil
@@ -206,33 +558,42 @@ func (s *logProducerService) processSingleFileMultiline(ctx context.Context, fil
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		if !lineComplete && !flushTrailing {
+			// The writer is in the middle of this line; it is read again, whole, next time
+			break
+		}
 		linesRead++
-		lineOffset := int64(len(line)) + 1
 
 		select {
 		case <-ctx.Done():
 			log.Info().Str("file", filePath).Msg("Context cancelled during multiline file processing.")
-			finalizeEntry()
-			return linesRead, currentOffset, entries, ctx.Err()
+			finalizeEntry(currentOffset)
+			return linesRead, endAt(currentOffset), entries, ctx.Err()
 		default:
 			// Continue processing
 		}
 
-		headerInfo, isHeader := s.parser.ParseHeader(line)
+		headerInfo, isHeader := logParser.ParseHeader(line)
 
 		if isHeader {
 			// === Là dòng Header ===
 			log.Trace().Str("file", filePath).Msg("Detected header line")
 			// 1. Hoàn thiện entry trước đó (nếu có)
-			finalizeEntry()
+			finalizeEntry(currentOffset)
+			entryStart = currentOffset
 
 			// 2. Bắt đầu entry mới
+			timestamp, inferred := s.timestamps.Resolve(headerInfo, location, previousTimestamp)
+			previousTimestamp = timestamp
 			currentEntry = &model.LogEntry{
-				Timestamp:   headerInfo.Timestamp,
-				Level:       headerInfo.Level,
-				Component:   headerInfo.Component,
-				Application: appID,
-				SourceFile:  filePath,
+				Timestamp:         timestamp,
+				TimestampInferred: inferred,
+				Level:             headerInfo.Level,
+				Component:         headerInfo.Component,
+				Application:       pathMeta.Application,
+				ContainerID:       pathMeta.ContainerID,
+				Attempt:           pathMeta.Attempt,
+				SourceFile:        filePath,
 			}
 			// 3. Thêm content và raw của dòng header vào buffer
 			contentBuffer.WriteString(headerInfo.InitialContent)
@@ -249,40 +610,156 @@ func (s *logProducerService) processSingleFileMultiline(ctx context.Context, fil
 			} else {
 				log.Warn().Str("file", filePath).Str("line", line).Msg("Orphan continuation line detected")
 				orphanEntry := model.LogEntry{
-					Timestamp:   time.Now().UTC(),
-					Level:       "UNKNOWN",
-					Component:   "ORPHAN",
-					Content:     line,
-					Application: appID,
-					SourceFile:  filePath,
-					Raw:         line,
+					Timestamp:         previousTimestamp,
+					TimestampInferred: true,
+					Level:             "UNKNOWN",
+					Component:         "ORPHAN",
+					Content:           line,
+					Application:       pathMeta.Application,
+					ContainerID:       pathMeta.ContainerID,
+					Attempt:           pathMeta.Attempt,
+					SourceFile:        filePath,
+					Raw:               line,
 				}
-				entries = append(entries, orphanEntry)
+				addEntry(orphanEntry, currentOffset, currentOffset+lineOffset)
 			}
 		}
 		currentOffset += lineOffset
 	}
 
 	if err := scanner.Err(); err != nil {
-		finalizeEntry()
-		return linesRead, currentOffset, entries, fmt.Errorf("error reading file %s: %w", filePath, err)
+		finalizeEntry(currentOffset)
+		return linesRead, endAt(currentOffset), entries, fmt.Errorf("error reading file %s: %w", filePath, err)
 	}
 
-	finalizeEntry()
+	if !flushTrailing {
+		if currentEntry != nil {
+			log.Debug().Str("file", filePath).Int64("offset", entryStart).Msg("Holding back trailing entry until the file is idle")
+			return linesRead, endAt(entryStart), entries, nil
+		}
+		return linesRead, endAt(currentOffset), entries, nil
+	}
+	finalizeEntry(currentOffset)
 
 	log.Debug().Str("file", filePath).Int64("lines_read", linesRead).Int("entries_created", len(entries)).Msg("Finished processing file")
-	return linesRead, currentOffset, entries, nil
+	return linesRead, endAt(currentOffset), entries, nil
 }
 
-func (s *logProducerService) sendBatch(ctx context.Context, batch []model.LogEntry) error {
+// enrichEntry adds what is derived from a complete entry's content: its event template, fields and stack trace.
+func enrichEntry(entry *model.LogEntry, templates parser.TemplateMatcher, fields parser.FieldExtractor) {
+	if eventID, params, ok := templates.Match(entry.Content); ok {
+		entry.EventID = eventID
+		entry.EventParams = params
+	}
+	entry.Fields = fields.Extract(entry.Content)
+	if trace, ok := parser.ParseStackTrace(entry.Content); ok {
+		entry.StackTrace = trace
+	}
+}
+
+// sendBatch produces the batch to Kafka and reports which entries were acknowledged.
+func (s *logProducerService) sendBatch(ctx context.Context, batch []pendingEntry) ([]bool, error) {
+	delivered := make([]bool, len(batch))
 	if len(batch) == 0 {
-		return nil
+		return delivered, nil
 	}
+	logs := make([]model.LogEntry, len(batch))
+	for i, pending := range batch {
+		logs[i] = pending.entry
+	}
+
 	log.Debug().Int("batch_size", len(batch)).Msg("Sending batch to Kafka...")
-	err := s.producer.Produce(ctx, batch)
+	err := s.producer.Produce(ctx, logs)
+	var deliveryErr *kafka.DeliveryError
+	switch {
+	case err == nil:
+		for i := range delivered {
+			delivered[i] = true
+		}
+	case errors.As(err, &deliveryErr):
+		for i := range delivered {
+			delivered[i] = !deliveryErr.Failed[i]
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("kafka produce error: %w", err)
+		return delivered, fmt.Errorf("kafka produce error: %w", err)
 	}
 	log.Debug().Int("batch_size", len(batch)).Msg("Successfully sent batch to Kafka.")
-	return nil
+	return delivered, nil
+}
+
+// logStream is a sequence of log lines: a plain file, a decompressed file, an archive member or an ingest request.
+type logStream struct {
+	key     string // File state key
+	path    string // Log file the lines belong to, for path metadata and SourceFile
+	reader  io.Reader
+	modTime time.Time
+	parser  parser.LogParser     // Chosen by the registry for path if nil
+	meta    *parser.PathMetadata // Taken from path if nil
+}
+
+// fileRead is what was read from one log stream in a run.
+type fileRead struct {
+	key       string
+	linesRead int64
+	bytesRead int64 // Decompressed for compressed files and archive members
+	entries   []pendingEntry
+	final     filestate.FileState // State to commit once every entry is delivered
+
+	renamedFrom string              // Path the file was tracked under before a rename, whose state is dropped
+	resumed     filestate.FileState // State the read resumed from after a rename
+}
+
+// pendingEntry is a log entry waiting for delivery, with the state of its stream to commit once it is delivered.
+type pendingEntry struct {
+	entry  model.LogEntry
+	key    string
+	commit filestate.FileState
+}
+
+// fileProgress tracks the delivery of the entries read from one file during a run.
+type fileProgress struct {
+	remaining int                 // Entries not yet delivered
+	final     filestate.FileState // State once every entry is delivered, including trailing lines that formed none
+	failed    bool                // An entry was not delivered, so the offset must not move past it
+}
+
+// pruneFileState drops the state of files that are gone: not among logFiles, the files discovered in a
+// full pass, and no longer on disk. It returns the dropped keys. Archive members go with their archive.
+func pruneFileState(state filestate.FileProcessState, logFiles []string) []string {
+	discovered := make(map[string]bool, len(logFiles))
+	for _, file := range logFiles {
+		discovered[file] = true
+	}
+	var pruned []string
+	for key := range state {
+		if !isDiscoveredOrOnDisk(key, discovered) {
+			delete(state, key)
+			pruned = append(pruned, key)
+		}
+	}
+	return pruned
+}
+
+// isDiscoveredOrOnDisk reports whether the file of a state key was discovered or still exists, e.g. in
+// a directory that could not be read. Keys of archive members are "<archive>#<member>"; paths may
+// contain '#' themselves, so every split is tried.
+func isDiscoveredOrOnDisk(key string, discovered map[string]bool) bool {
+	for end := len(key); end >= 0; end = strings.LastIndexByte(key[:end], '#') {
+		file := key[:end]
+		if discovered[file] {
+			return true
+		}
+		if _, err := os.Lstat(file); !os.IsNotExist(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryID derives a stable ID from where the entry was read, so an entry that is sent again
+// (e.g. its acknowledgement was lost) overwrites the stored copy instead of duplicating it.
+func entryID(key string, position filestate.FileState, offset int64, raw string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s\x00%d:%d\x00%d\x00%s", key, position.Device, position.Inode, offset, raw)))
+	return hex.EncodeToString(sum[:])
 }
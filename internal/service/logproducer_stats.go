@@ -0,0 +1,113 @@
+package service
+
+import (
+	"os"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/filestate"
+	"sync"
+	"time"
+)
+
+// producerStats accumulates what the producer did across runs, for the stats endpoint.
+type producerStats struct {
+	mu             sync.Mutex
+	runs           int64
+	failedRuns     int64
+	filesProcessed int64
+	linesRead      int64
+	entriesSent    int64
+	entriesFailed  int64
+	lastRun        runStats
+	lastRunAt      time.Time
+	lag            map[string]int64 // Undelivered bytes per plain log file, as of the last run that covered it
+}
+
+// runStats is what a single processFiles call did.
+type runStats struct {
+	files         int
+	linesRead     int64
+	entriesSent   int64
+	entriesFailed int64
+	duration      time.Duration
+	failed        bool
+}
+
+func (p *producerStats) recordRun(run runStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.runs++
+	if run.failed {
+		p.failedRuns++
+	}
+	p.filesProcessed += int64(run.files)
+	p.linesRead += run.linesRead
+	p.entriesSent += run.entriesSent
+	p.entriesFailed += run.entriesFailed
+	p.lastRun = run
+	p.lastRunAt = time.Now()
+}
+
+// recordLag updates the lag of logFiles from their size and committed offset. Compressed files and
+// archives are left out: their size on disk says nothing about the bytes left to read. The lag of
+// other files is dropped once they are no longer in state, e.g. deleted, excluded or renamed.
+func (p *producerStats) recordLag(logFiles []string, state filestate.FileProcessState) {
+	lag := make(map[string]int64, len(logFiles))
+	for _, filePath := range logFiles {
+		if isArchive(filePath) || isCompressedLog(filePath) {
+			continue
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			lag[filePath] = -1 // Gone
+			continue
+		}
+		lag[filePath] = max(info.Size()-state[filePath].Offset, 0)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.lag == nil {
+		p.lag = make(map[string]int64)
+	}
+	for filePath := range p.lag {
+		if _, tracked := state[filePath]; !tracked {
+			if _, read := lag[filePath]; !read {
+				delete(p.lag, filePath)
+			}
+		}
+	}
+	for filePath, bytes := range lag {
+		if bytes < 0 {
+			delete(p.lag, filePath)
+			continue
+		}
+		p.lag[filePath] = bytes
+	}
+}
+
+func (s *logProducerService) Stats() dto.ProducerStatsResponse {
+	p := &s.stats
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := dto.ProducerStatsResponse{
+		Runs:              p.runs,
+		FailedRuns:        p.failedRuns,
+		FilesProcessed:    p.filesProcessed,
+		LinesRead:         p.linesRead,
+		EntriesSent:       p.entriesSent,
+		EntriesFailed:     p.entriesFailed,
+		LastRunAt:         p.lastRunAt,
+		LastRunDurationMs: p.lastRun.duration.Milliseconds(),
+	}
+	if seconds := p.lastRun.duration.Seconds(); seconds > 0 {
+		stats.LastRunEntriesPerSecond = float64(p.lastRun.entriesSent) / seconds
+	}
+	for _, bytes := range p.lag {
+		stats.LagBytes += bytes
+		if bytes > 0 {
+			stats.LaggingFiles++
+		}
+	}
+	return stats
+}
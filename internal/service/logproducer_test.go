@@ -0,0 +1,228 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/filestate"
+	"skeleton-internship-backend/internal/kafka"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+	"skeleton-internship-backend/internal/service"
+	"skeleton-internship-backend/internal/telemetry"
+)
+
+// fakeProducer records the entries it acknowledges. Entries whose content is in fail are not.
+type fakeProducer struct {
+	kafka.LogProducer
+	mu   sync.Mutex
+	fail map[string]bool
+	sent []model.LogEntry
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, logs []model.LogEntry) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	failed := make([]bool, len(logs))
+	anyFailed := false
+	for i, entry := range logs {
+		if p.fail[entry.Content] {
+			failed[i], anyFailed = true, true
+			continue
+		}
+		p.sent = append(p.sent, entry)
+	}
+	if anyFailed {
+		return &kafka.DeliveryError{Failed: failed, Err: errors.New("not enough in-sync replicas")}
+	}
+	return nil
+}
+
+func (p *fakeProducer) contents() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var contents []string
+	for _, entry := range p.sent {
+		contents = append(contents, entry.Content)
+	}
+	return contents
+}
+
+// newTestProducerService reads the *.log files of dir and keeps its state in dir/state.json.
+func newTestProducerService(t *testing.T, dir string, producer kafka.LogProducer, idleTimeout time.Duration) (service.LogProducerService, filestate.Manager) {
+	t.Helper()
+	cfg := &config.Config{LogProcessor: config.LogProcessorConfig{
+		Sources:              []config.LogSource{{Name: "test", Root: dir, Include: []string{"*.log"}}},
+		BatchSize:            100,
+		Workers:              2,
+		MultilineIdleTimeout: idleTimeout,
+		IngestTimeout:        time.Second,
+	}}
+	parsers, err := parser.NewParserRegistry(cfg)
+	require.NoError(t, err)
+	timestamps, err := parser.NewTimestampResolver(cfg)
+	require.NoError(t, err)
+	templates, err := parser.NewTemplateMatcherFromTemplates(nil)
+	require.NoError(t, err)
+	stateMgr := filestate.NewManager(filepath.Join(dir, "state.json"))
+	s, err := service.NewLogProducerService(cfg, stateMgr, parsers, timestamps, templates, parser.NewFieldExtractor(), producer, telemetry.NewMetrics())
+	require.NoError(t, err)
+	return s, stateMgr
+}
+
+func appendLines(t *testing.T, path string, lines string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(lines)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestProcessLogs_ResumedLineInheritsPreviousTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	producer := &fakeProducer{}
+	s, _ := newTestProducerService(t, dir, producer, time.Nanosecond)
+	path := filepath.Join(dir, "app.log")
+
+	appendLines(t, path, "22/01/24 14:30:45 INFO executor.Executor: Running task 0.0\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+	// Read in the next run, without a header of its own
+	appendLines(t, path, "continued after the run\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+
+	require.Len(t, producer.sent, 2)
+	assert.Equal(t, "ORPHAN", producer.sent[1].Component)
+	assert.Equal(t, time.Date(2022, 1, 24, 14, 30, 45, 0, time.UTC), producer.sent[1].Timestamp.UTC())
+}
+
+func TestProcessLogs_DrainsRotatedFileBeforeTheNewOne(t *testing.T) {
+	dir := t.TempDir()
+	producer := &fakeProducer{}
+	s, stateMgr := newTestProducerService(t, dir, producer, time.Nanosecond)
+	path := filepath.Join(dir, "app.log")
+
+	appendLines(t, path, "22/01/24 14:30:45 INFO executor.Executor: read before rotation\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+	// Written after the run, then rotated away: only app.log.1 still has it
+	appendLines(t, path, "22/01/24 14:30:46 INFO executor.Executor: written before rotation\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLines(t, path, "22/01/24 14:30:47 INFO executor.Executor: written after rotation\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+
+	assert.Equal(t, []string{"read before rotation", "written before rotation", "written after rotation"}, producer.contents())
+	state, err := stateMgr.LoadState()
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), state[path].Offset)
+}
+
+func TestProcessLogs_PartialDeliveryCommitsUpToFirstFailure(t *testing.T) {
+	dir := t.TempDir()
+	producer := &fakeProducer{fail: map[string]bool{"a2": true, "b3": true}}
+	s, stateMgr := newTestProducerService(t, dir, producer, time.Nanosecond)
+	lines := map[string][]string{
+		"a.log": {"22/01/24 14:30:45 INFO executor.Executor: a1\n", "22/01/24 14:30:46 INFO executor.Executor: a2\n", "22/01/24 14:30:47 INFO executor.Executor: a3\n"},
+		"b.log": {"22/01/24 14:30:45 INFO executor.Executor: b1\n", "22/01/24 14:30:46 INFO executor.Executor: b2\n", "22/01/24 14:30:47 INFO executor.Executor: b3\n"},
+	}
+	for name, fileLines := range lines {
+		appendLines(t, filepath.Join(dir, name), strings.Join(fileLines, ""))
+	}
+
+	var deliveryErr *kafka.DeliveryError
+	require.ErrorAs(t, s.ProcessLogs(context.Background()), &deliveryErr)
+	assert.ElementsMatch(t, []string{"a1", "a3", "b1", "b2"}, producer.contents())
+
+	// Each file's offset stops before its first entry Kafka did not acknowledge
+	state, err := stateMgr.LoadState()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(lines["a.log"][0])), state[filepath.Join(dir, "a.log")].Offset)
+	assert.Equal(t, int64(len(lines["b.log"][0]+lines["b.log"][1])), state[filepath.Join(dir, "b.log")].Offset)
+	firstRun := producer.sent
+
+	producer.fail = nil
+	producer.sent = nil
+	require.NoError(t, s.ProcessLogs(context.Background()))
+	// a3 follows a2 in its file, so it is sent again, with the same ID to replace the stored copy
+	assert.ElementsMatch(t, []string{"a2", "a3", "b3"}, producer.contents())
+	for _, entry := range producer.sent {
+		if entry.Content == "a3" {
+			assert.Contains(t, firstRun, entry)
+		}
+	}
+}
+
+func TestProcessLogs_HoldsBackTrailingEntryUntilComplete(t *testing.T) {
+	dir := t.TempDir()
+	producer := &fakeProducer{}
+	s, _ := newTestProducerService(t, dir, producer, time.Hour)
+	path := filepath.Join(dir, "app.log")
+
+	// Cut off at the end of the file while the stack trace is being written
+	appendLines(t, path, "22/01/24 14:30:45 ERROR executor.Executor: Exception in task 0.0\n"+
+		"java.lang.IllegalStateException: boom\n"+
+		"\tat org.apache.spark.executor.Executor$TaskRunner.run(Executor.scala:338)\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+	assert.Empty(t, producer.sent)
+
+	appendLines(t, path, "\tat java.lang.Thread.run(Thread.java:748)\n"+
+		"22/01/24 14:30:46 INFO executor.Executor: Finished task 1.0\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+
+	// The trace is resumed as one entry, not orphan lines; the new trailing entry is held back in turn
+	require.Len(t, producer.sent, 1)
+	assert.Equal(t, "ERROR", producer.sent[0].Level)
+	assert.Equal(t, "Exception in task 0.0\n"+
+		"java.lang.IllegalStateException: boom\n"+
+		"\tat org.apache.spark.executor.Executor$TaskRunner.run(Executor.scala:338)\n"+
+		"\tat java.lang.Thread.run(Thread.java:748)", producer.sent[0].Content)
+}
+
+func TestProcessLogs_FlushesTrailingEntryOnceIdle(t *testing.T) {
+	dir := t.TempDir()
+	producer := &fakeProducer{}
+	s, _ := newTestProducerService(t, dir, producer, time.Hour)
+	path := filepath.Join(dir, "app.log")
+
+	appendLines(t, path, "22/01/24 14:30:45 ERROR executor.Executor: Exception in task 0.0\n"+
+		"java.lang.IllegalStateException: boom\n")
+	require.NoError(t, s.ProcessLogs(context.Background()))
+	assert.Empty(t, producer.sent)
+
+	// Nothing was written for longer than MultilineIdleTimeout
+	idleSince := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, idleSince, idleSince))
+	require.NoError(t, s.ProcessLogs(context.Background()))
+
+	require.Len(t, producer.sent, 1)
+	assert.Equal(t, "Exception in task 0.0\njava.lang.IllegalStateException: boom", producer.sent[0].Content)
+}
+
+func TestProcessLogs_LagForgetsFilesThatAreGone(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newTestProducerService(t, dir, &fakeProducer{}, time.Hour)
+	for _, name := range []string{"a.log", "b.log"} {
+		// The trailing entry is held back, so both files lag behind by one line
+		appendLines(t, filepath.Join(dir, name), "22/01/24 14:30:45 INFO executor.Executor: Running task 0.0\n")
+	}
+	require.NoError(t, s.ProcessLogs(context.Background()))
+	require.Equal(t, 2, s.Stats().LaggingFiles)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.log")))
+	require.NoError(t, s.ProcessLogs(context.Background()))
+
+	stats := s.Stats()
+	assert.Equal(t, 1, stats.LaggingFiles)
+	assert.Equal(t, int64(len("22/01/24 14:30:45 INFO executor.Executor: Running task 0.0\n")), stats.LagBytes)
+}
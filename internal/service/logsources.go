@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/parser"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// logSource is a config.LogSource with its patterns checked and compiled.
+type logSource struct {
+	name        string
+	root        string
+	include     []string
+	exclude     []string
+	recursive   bool
+	application *regexp.Regexp // nil to keep the application found by parser.ExtractPathMetadata
+}
+
+func newLogSources(cfgSources []config.LogSource) ([]*logSource, error) {
+	sources := make([]*logSource, 0, len(cfgSources))
+	for _, cfgSource := range cfgSources {
+		if cfgSource.Root == "" {
+			return nil, fmt.Errorf("invalid log source %q: root is required", cfgSource.Name)
+		}
+		for _, pattern := range append(append([]string(nil), cfgSource.Include...), cfgSource.Exclude...) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in log source %q: %w", pattern, cfgSource.Name, err)
+			}
+		}
+		source := &logSource{
+			name:      cfgSource.Name,
+			root:      filepath.Clean(cfgSource.Root),
+			include:   cfgSource.Include,
+			exclude:   cfgSource.Exclude,
+			recursive: cfgSource.Recursive,
+		}
+		if cfgSource.ApplicationPattern != "" {
+			application, err := regexp.Compile(cfgSource.ApplicationPattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid application pattern in log source %q: %w", cfgSource.Name, err)
+			}
+			source.application = application
+		}
+		log.Info().Str("source", source.name).Str("root", source.root).Strs("include", source.include).Strs("exclude", source.exclude).Bool("recursive", source.recursive).Msg("Log source configured")
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// matchesAny reports whether a pattern matches rel or its base name.
+func matchesAny(patterns []string, rel string) bool {
+	base := filepath.Base(rel)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rel returns path relative to the source root, and false if path is not inside it.
+func (src *logSource) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(src.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// selects reports whether the file at rel is read.
+func (src *logSource) selects(rel string) bool {
+	if matchesAny(src.exclude, rel) {
+		return false
+	}
+	if len(src.include) > 0 {
+		return matchesAny(src.include, rel)
+	}
+	dir, name := filepath.Split(rel)
+	if dir == "" {
+		// Tarball of whole application folders
+		return isArchive(name)
+	}
+	return isLogFile(name) && (src.recursive || isApplicationDir(filepath.Base(dir)))
+}
+
+// descends reports whether the directory at rel is scanned.
+func (src *logSource) descends(rel string) bool {
+	if matchesAny(src.exclude, rel) {
+		return false
+	}
+	if src.recursive {
+		return true
+	}
+	if strings.Contains(rel, string(filepath.Separator)) {
+		return false
+	}
+	return len(src.include) > 0 || isApplicationDir(rel)
+}
+
+// scan walks the source from start, the root or a directory inside it, and returns the log files
+// it selects and the directories it descends into, start included.
+func (src *logSource) scan(start string) (files []string, dirs []string, err error) {
+	err = filepath.WalkDir(start, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == start {
+				return err
+			}
+			log.Warn().Err(err).Str("source", src.name).Str("path", path).Msg("Failed to read log directory")
+			return nil
+		}
+		rel, ok := src.rel(path)
+		if !ok {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() {
+			if rel != "." && !src.descends(rel) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		}
+		if entry.Type().IsRegular() && src.selects(rel) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, dirs, err
+}
+
+// sourceFor returns the source with the deepest root containing path.
+func (s *logProducerService) sourceFor(path string) (*logSource, string, bool) {
+	var found *logSource
+	var foundRel string
+	for _, src := range s.sources {
+		if rel, ok := src.rel(path); ok && (found == nil || len(src.root) > len(found.root)) {
+			found, foundRel = src, rel
+		}
+	}
+	return found, foundRel, found != nil
+}
+
+// pathMetadata is parser.ExtractPathMetadata with the application taken from the source's
+// application pattern, when it has one that matches.
+func (s *logProducerService) pathMetadata(filePath string) parser.PathMetadata {
+	meta := parser.ExtractPathMetadata(filePath)
+	src, rel, ok := s.sourceFor(filePath)
+	if !ok || src.application == nil {
+		return meta
+	}
+	matches := src.application.FindStringSubmatch(filepath.ToSlash(rel))
+	switch {
+	case len(matches) > 1 && matches[1] != "":
+		meta.Application = matches[1]
+	case len(matches) == 1:
+		meta.Application = matches[0]
+	}
+	return meta
+}
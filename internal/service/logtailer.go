@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultTailDebounce = 500 * time.Millisecond
+
+// Tail processes log files as they change instead of waiting for the next scheduled run.
+// Offsets come from the same file state as ProcessLogs, so switching modes neither skips nor repeats lines.
+func (s *logProducerService) Tail(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create file watcher, tail mode disabled")
+		return
+	}
+	defer watcher.Close()
+
+	if s.watchSources(watcher) == 0 {
+		log.Error().Msg("Failed to watch any log source root, tail mode disabled")
+		return
+	}
+
+	debounce := s.cfg.TailDebounce
+	if debounce <= 0 {
+		debounce = defaultTailDebounce
+	}
+	log.Info().Int("sources", len(s.sources)).Dur("debounce", debounce).Msg("Tailing log sources")
+
+	pending := make(map[string]bool)
+	rescan := false
+	// Files whose trailing entry may have been held back; they are read once more after the idle timeout
+	// even if nothing else is written to them
+	recheck := make(map[string]bool)
+	recheckTimer := time.NewTimer(s.cfg.MultilineIdleTimeout)
+	recheckTimer.Stop()
+	scheduleRecheck := func(files map[string]bool) {
+		for file := range files {
+			recheck[file] = true
+		}
+		if !recheckTimer.Stop() {
+			select {
+			case <-recheckTimer.C:
+			default:
+			}
+		}
+		recheckTimer.Reset(s.cfg.MultilineIdleTimeout + debounce)
+	}
+	// fullPass processes every log file, as after startup or dropped notifications
+	fullPass := func() {
+		if err := s.ProcessLogs(ctx); err != nil {
+			log.Error().Err(err).Msg("Error during full log directory pass")
+		}
+		if files, _, err := s.findLogFiles(); err == nil {
+			all := make(map[string]bool, len(files))
+			for _, file := range files {
+				all[file] = true
+			}
+			scheduleRecheck(all)
+		}
+	}
+
+	// Catch up on everything written while the service was down
+	fullPass()
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	timerArmed := false
+	arm := func() {
+		if !timerArmed {
+			timer.Reset(debounce)
+			timerArmed = true
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			recheckTimer.Stop()
+			log.Info().Msg("Log tailer stopping due to context cancellation.")
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if s.handleWatchEvent(watcher, event, pending) {
+				arm()
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// Some notifications were dropped, so only a full pass is guaranteed to see every change
+				log.Warn().Err(err).Msg("File watcher overflowed, rescanning log sources")
+				rescan = true
+				arm()
+				continue
+			}
+			log.Error().Err(err).Msg("File watcher error")
+
+		case <-timer.C:
+			timerArmed = false
+			if rescan {
+				s.watchSources(watcher)
+				fullPass()
+				rescan = false
+				pending = make(map[string]bool)
+				continue
+			}
+
+			s.processChangedFiles(ctx, pending)
+			scheduleRecheck(pending)
+			pending = make(map[string]bool)
+
+		case <-recheckTimer.C:
+			s.processChangedFiles(ctx, recheck)
+			recheck = make(map[string]bool)
+		}
+	}
+}
+
+func (s *logProducerService) processChangedFiles(ctx context.Context, changed map[string]bool) {
+	files := make([]string, 0, len(changed))
+	for file := range changed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	s.processLock.Lock()
+	err := s.processFiles(ctx, files, false)
+	s.processLock.Unlock()
+	if err != nil {
+		log.Error().Err(err).Int("file_count", len(files)).Msg("Error processing changed log files")
+	}
+}
+
+// watchSources adds a watch on every directory the sources scan and returns how many roots are watched.
+func (s *logProducerService) watchSources(watcher *fsnotify.Watcher) int {
+	roots := 0
+	for _, src := range s.sources {
+		_, dirs, err := src.scan(src.root)
+		if err != nil {
+			log.Error().Err(err).Str("source", src.name).Str("root", src.root).Msg("Failed to scan log source to watch")
+			continue
+		}
+		roots++
+		for _, dir := range dirs {
+			if err := watcher.Add(dir); err != nil {
+				log.Warn().Err(err).Str("dir", dir).Msg("Failed to watch log directory")
+			}
+		}
+	}
+	return roots
+}
+
+// handleWatchEvent queues the files affected by event and reports whether any were queued.
+func (s *logProducerService) handleWatchEvent(watcher *fsnotify.Watcher, event fsnotify.Event, pending map[string]bool) bool {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	src, rel, ok := s.sourceFor(event.Name)
+	if !ok || rel == "." {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if !src.descends(rel) {
+				return false
+			}
+			// Files and subdirectories may have been created before the watch was in place
+			files, dirs, err := src.scan(event.Name)
+			if err != nil {
+				log.Warn().Err(err).Str("dir", event.Name).Msg("Failed to read new log directory")
+			}
+			for _, dir := range dirs {
+				if err := watcher.Add(dir); err != nil {
+					log.Warn().Err(err).Str("dir", dir).Msg("Failed to watch new log directory")
+				}
+			}
+			log.Info().Str("source", src.name).Str("dir", event.Name).Msg("Watching new log directory")
+			for _, file := range files {
+				pending[file] = true
+			}
+			return len(files) > 0
+		}
+	}
+
+	if src.selects(rel) {
+		pending[event.Name] = true
+		return true
+	}
+	return false
+}
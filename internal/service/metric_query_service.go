@@ -13,7 +13,6 @@ import (
 type MetricQueryService interface {
 	GetSummary(ctx context.Context, req dto.MetricSummaryRequest) (*dto.MetricSummaryResponse, error)
 	GetTimeseries(ctx context.Context, req dto.MetricTimeseriesRequest) (*dto.MetricTimeseriesResponse, error)
-	GetApplications(ctx context.Context, req dto.ApplicationListRequest) (*dto.ApplicationListResponse, error)
 	GetDistribution(ctx context.Context, req dto.MetricDistributionRequest) (*dto.MetricDistributionResponse, error)
 }
 
@@ -61,7 +60,7 @@ func (s *metricQueryService) GetTimeseries(ctx context.Context, req dto.MetricTi
 	}
 
 	allowedGroupBy := map[string]bool{
-		"level": true, "component": true, "error_key": true, "application": true, "total": true, "": true, // Chấp nhận rỗng hoặc 'total'
+		"level": true, "component": true, "error_key": true, "event_id": true, "exception_class": true, "root_cause": true, "container_id": true, "attempt": true, "application": true, "total": true, "": true, // Chấp nhận rỗng hoặc 'total'
 	}
 	if req.GroupBy == "" {
 		req.GroupBy = "total"
@@ -82,18 +81,6 @@ func (s *metricQueryService) GetTimeseries(ctx context.Context, req dto.MetricTi
 	return s.metricRepo.GetTimeseriesMetrics(ctx, req)
 }
 
-// GetApplications validates input and calls the repository
-func (s *metricQueryService) GetApplications(ctx context.Context, req dto.ApplicationListRequest) (*dto.ApplicationListResponse, error) {
-	if req.StartTime.IsZero() || req.EndTime.IsZero() {
-		return nil, errors.New("startTime and endTime are required")
-	}
-	if req.EndTime.Before(req.StartTime) {
-		return nil, errors.New("endTime cannot be before startTime")
-	}
-	log.Info().Time("start", req.StartTime).Time("end", req.EndTime).Msg("Getting distinct applications")
-	return s.metricRepo.GetDistinctApplications(ctx, req)
-}
-
 func (s *metricQueryService) GetDistribution(ctx context.Context, req dto.MetricDistributionRequest) (*dto.MetricDistributionResponse, error) {
 	// Validate metric name
 	allowedMetrics := map[string]bool{"log_event": true, "error_event": true}
@@ -102,7 +89,7 @@ func (s *metricQueryService) GetDistribution(ctx context.Context, req dto.Metric
 	}
 
 	// Validate dimension
-	allowedDimensions := map[string]bool{"level": true, "component": true, "error_key": true, "application": true}
+	allowedDimensions := map[string]bool{"level": true, "component": true, "error_key": true, "event_id": true, "exception_class": true, "root_cause": true, "container_id": true, "attempt": true, "application": true}
 	if !allowedDimensions[req.Dimension] {
 		return nil, fmt.Errorf("invalid dimension for distribution: %s", req.Dimension)
 	}
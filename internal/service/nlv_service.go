@@ -8,6 +8,7 @@ import (
 	"skeleton-internship-backend/internal/model"
 	"skeleton-internship-backend/internal/repository"
 	"skeleton-internship-backend/internal/store"
+	"skeleton-internship-backend/internal/telemetry"
 	"strings"
 	"time"
 
@@ -26,12 +27,13 @@ type nlvService struct {
 	logRepo       repository.LogRepository
 	convoStore    store.ConversationStore
 	schemaContext string
+	metrics       *telemetry.Metrics
 }
 
-func NewNLVService(llmService LLMService, metricRepo repository.MetricRepository, logRepo repository.LogRepository, convoStore store.ConversationStore) NLVService {
+func NewNLVService(llmService LLMService, metricRepo repository.MetricRepository, logRepo repository.LogRepository, convoStore store.ConversationStore, metrics *telemetry.Metrics) NLVService {
 	schemaCtx := `
-        TimescaleDB table 'log_metric_events': columns time (timestamp), metric_name (text, values: 'log_event', 'error_event'), application (text), tags (jsonb keys: 'level', 'component', 'error_key', 'parse_status').
-        Elasticsearch index 'applogs-*': fields @timestamp, level (keyword), component (keyword), application (keyword), content (text), raw_log (text).
+        TimescaleDB table 'log_metric_events': columns time (timestamp), metric_name (text, values: 'log_event', 'error_event'), application (text), tags (jsonb keys: 'level', 'component', 'error_key', 'event_id', 'exception_class', 'root_cause', 'container_id', 'attempt', 'parse_status'). 'error_key' is a fingerprint hash grouping identical errors; use 'exception_class' or 'root_cause' for readable exception names.
+        Elasticsearch index 'applogs-*': fields @timestamp, level (keyword), component (keyword), application (keyword), container_id (keyword), attempt (long), event_id (keyword), content (text), raw_log (text), stack_trace.fingerprint (keyword), stack_trace.exceptions.class (keyword).
     `
 	return &nlvService{
 		llmService:    llmService,
@@ -39,10 +41,22 @@ func NewNLVService(llmService LLMService, metricRepo repository.MetricRepository
 		logRepo:       logRepo,
 		convoStore:    convoStore,
 		schemaContext: schemaCtx,
+		metrics:       metrics,
 	}
 }
 
 func (s *nlvService) ProcessNaturalLanguageQuery(ctx context.Context, req dto.NLVQueryRequest) (*dto.NLVQueryResponse, error) {
+	start := time.Now()
+	resp, err := s.processQuery(ctx, req)
+	resultType := "error"
+	if err == nil && resp != nil {
+		resultType = resp.ResultType
+	}
+	s.metrics.NLVQueryDuration.WithLabelValues(resultType).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+func (s *nlvService) processQuery(ctx context.Context, req dto.NLVQueryRequest) (*dto.NLVQueryResponse, error) {
 	log.Info().Str("query", req.Query).Msg("Processing NLV query")
 
 	var conversationId string
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"skeleton-internship-backend/config"
+	"skeleton-internship-backend/internal/kafka"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/parser"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// syslogRetryInterval is how long the syslog batch waits before being sent again after Kafka failed.
+const syslogRetryInterval = 2 * time.Second
+
+// SyslogService receives syslog messages over TCP and UDP and sends them to Kafka like log file entries.
+type SyslogService interface {
+	// Run listens on the configured addresses until ctx is cancelled. It returns at once if none are configured.
+	Run(ctx context.Context, wg *sync.WaitGroup)
+}
+
+type syslogService struct {
+	cfg          *config.SyslogConfig
+	batchSize    int
+	maxBatchWait time.Duration
+	idleTimeout  time.Duration
+	tcpSlots     chan struct{} // One per open TCP connection
+	timestamps   *parser.TimestampResolver
+	templates    parser.TemplateMatcher
+	fields       parser.FieldExtractor
+	producer     kafka.LogProducer
+	entries      chan model.LogEntry
+}
+
+func NewSyslogService(
+	cfg *config.Config,
+	timestamps *parser.TimestampResolver,
+	templates parser.TemplateMatcher,
+	fields parser.FieldExtractor,
+	producer kafka.LogProducer,
+) SyslogService {
+	batchSize := max(cfg.LogProcessor.BatchSize, 1)
+	idleTimeout := cfg.Syslog.TCPIdleTimeout
+	if idleTimeout <= 0 {
+		log.Warn().Dur("idle_timeout", idleTimeout).Msg("SYSLOG_TCP_IDLE_TIMEOUT must be positive, using 5m")
+		idleTimeout = 5 * time.Minute
+	}
+	return &syslogService{
+		cfg:          &cfg.Syslog,
+		batchSize:    batchSize,
+		maxBatchWait: cfg.LogProcessor.MaxBatchWait,
+		idleTimeout:  idleTimeout,
+		tcpSlots:     make(chan struct{}, max(cfg.Syslog.TCPMaxConnections, 1)),
+		timestamps:   timestamps,
+		templates:    templates,
+		fields:       fields,
+		producer:     producer,
+		// TCP senders block once this is full, UDP messages are dropped
+		entries: make(chan model.LogEntry, batchSize*4),
+	}
+}
+
+func (s *syslogService) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	if s.cfg.TCPAddress == "" && s.cfg.UDPAddress == "" {
+		return
+	}
+
+	var listeners sync.WaitGroup
+	listenCtx, stopListening := context.WithCancel(ctx)
+	if s.cfg.TCPAddress != "" {
+		listener, err := net.Listen("tcp", s.cfg.TCPAddress)
+		if err != nil {
+			log.Error().Err(err).Str("address", s.cfg.TCPAddress).Msg("Failed to listen for syslog over TCP")
+		} else {
+			log.Info().Str("address", listener.Addr().String()).Msg("Listening for syslog over TCP")
+			listeners.Add(1)
+			go s.serveTCP(listenCtx, listener, &listeners)
+		}
+	}
+	if s.cfg.UDPAddress != "" {
+		conn, err := net.ListenPacket("udp", s.cfg.UDPAddress)
+		if err != nil {
+			log.Error().Err(err).Str("address", s.cfg.UDPAddress).Msg("Failed to listen for syslog over UDP")
+		} else {
+			log.Info().Str("address", conn.LocalAddr().String()).Msg("Listening for syslog over UDP")
+			listeners.Add(1)
+			go s.serveUDP(listenCtx, conn, &listeners)
+		}
+	}
+
+	s.sendLoop(ctx)
+	stopListening()
+	listeners.Wait()
+	log.Info().Msg("Syslog listener stopped.")
+}
+
+// sendLoop batches received entries and sends them to Kafka until ctx is cancelled, then sends what is left.
+func (s *syslogService) sendLoop(ctx context.Context) {
+	var batch []model.LogEntry
+	maxBatchWait := s.maxBatchWait
+	if maxBatchWait <= 0 {
+		maxBatchWait = 5 * time.Second
+	}
+	ticker := time.NewTicker(maxBatchWait)
+	defer ticker.Stop()
+
+	send := func(sendCtx context.Context) {
+		for len(batch) > 0 {
+			err := s.producer.Produce(sendCtx, batch)
+			if err == nil {
+				batch = nil
+				return
+			}
+			// Entries keep their IDs, so the ones already delivered are overwritten rather than duplicated
+			log.Error().Err(err).Int("batch_size", len(batch)).Msg("Failed to send syslog messages to Kafka, retrying")
+			select {
+			case <-sendCtx.Done():
+				log.Warn().Int("batch_size", len(batch)).Msg("Dropping unsent syslog messages")
+				return
+			case <-time.After(syslogRetryInterval):
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Send what the listeners already accepted
+		drain:
+			for {
+				select {
+				case entry := <-s.entries:
+					batch = append(batch, entry)
+				default:
+					break drain
+				}
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			send(flushCtx)
+			cancel()
+			return
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				send(ctx)
+			}
+		case <-ticker.C:
+			send(ctx)
+		}
+	}
+}
+
+func (s *syslogService) serveTCP(ctx context.Context, listener net.Listener, wg *sync.WaitGroup) {
+	defer wg.Done()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var conns sync.WaitGroup
+	defer conns.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to accept syslog connection")
+			}
+			return
+		}
+		select {
+		case s.tcpSlots <- struct{}{}:
+		default:
+			log.Warn().Str("peer", hostOf(conn.RemoteAddr())).Int("max_connections", cap(s.tcpSlots)).Msg("Refusing syslog connection, too many are open")
+			conn.Close()
+			continue
+		}
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			defer func() { <-s.tcpSlots }()
+			s.handleTCPConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *syslogService) handleTCPConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	peer := hostOf(conn.RemoteAddr())
+	reader := bufio.NewReaderSize(conn, s.cfg.MaxMessageSize)
+	for {
+		// Each message must arrive within the idle timeout, so idle or trickling senders do not hold the connection
+		if err := conn.SetReadDeadline(time.Now().Add(s.idleTimeout)); err != nil {
+			log.Warn().Err(err).Str("peer", peer).Msg("Closing syslog connection")
+			return
+		}
+		frame, err := readSyslogFrame(reader, s.cfg.MaxMessageSize)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				log.Debug().Str("peer", peer).Msg("Closing idle syslog connection")
+				return
+			}
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				log.Warn().Err(err).Str("peer", peer).Msg("Closing syslog connection")
+			}
+			return
+		}
+		if strings.TrimSpace(frame) == "" {
+			continue
+		}
+		select {
+		case s.entries <- s.newEntry(frame, peer, time.Now()):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readSyslogFrame reads one message framed per RFC 6587: "<length> <message>" (octet counting) or a
+// message ending with a newline (non-transparent framing).
+func readSyslogFrame(reader *bufio.Reader, maxSize int) (string, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	if first[0] >= '1' && first[0] <= '9' {
+		lengthField, err := reader.ReadString(' ')
+		if err != nil {
+			return "", err
+		}
+		length, err := strconv.Atoi(lengthField[:len(lengthField)-1])
+		if err != nil || length > maxSize {
+			return "", fmt.Errorf("invalid syslog frame length %q", lengthField)
+		}
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return "", err
+		}
+		return string(frame), nil
+	}
+
+	line, err := reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("syslog message longer than %d bytes", maxSize)
+	}
+	if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+		return "", err
+	}
+	return string(line), nil
+}
+
+func (s *syslogService) serveUDP(ctx context.Context, conn net.PacketConn, wg *sync.WaitGroup) {
+	defer wg.Done()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, s.cfg.MaxMessageSize)
+	var dropped int64
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to read syslog datagram")
+			}
+			return
+		}
+		select {
+		case s.entries <- s.newEntry(string(buf[:n]), hostOf(addr), time.Now()):
+		default:
+			// UDP senders cannot be slowed down
+			if dropped++; dropped%1000 == 1 {
+				log.Warn().Int64("dropped", dropped).Msg("Syslog queue full, dropping UDP messages")
+			}
+		}
+	}
+}
+
+// newEntry turns a syslog message into a log entry. Messages that are not valid syslog are kept whole.
+func (s *syslogService) newEntry(frame, peer string, received time.Time) model.LogEntry {
+	frame = strings.TrimRight(frame, "\r\n\x00")
+	entry := model.LogEntry{
+		ID:          uuid.NewString(),
+		Application: s.cfg.Application,
+		Raw:         frame,
+	}
+	msg, err := parser.ParseSyslog(frame)
+	if err != nil {
+		log.Debug().Err(err).Str("peer", peer).Msg("Received malformed syslog message")
+		entry.Timestamp = received.UTC()
+		entry.TimestampInferred = true
+		entry.Level = "UNKNOWN"
+		entry.Component = "syslog"
+		entry.Content = frame
+		entry.Hostname = peer
+		entry.SourceFile = path.Join("syslog", peer)
+		enrichEntry(&entry, s.templates, s.fields)
+		return entry
+	}
+
+	entry.Hostname = msg.Hostname
+	if entry.Hostname == "" {
+		entry.Hostname = peer
+	}
+	// Timezone rules can target a host by its name, e.g. LOG_PROCESSOR_TIMEZONE_RULES="web-*=Asia/Ho_Chi_Minh"
+	entry.SourceFile = path.Join("syslog", entry.Hostname)
+	location := s.timestamps.LocationFor(path.Join(entry.SourceFile, "messages"))
+	entry.Timestamp, entry.TimestampInferred = s.timestamps.Resolve(&msg.Header, location, received)
+	entry.Level = msg.Header.Level
+	entry.Component = msg.Header.Component
+	entry.Content = msg.Header.InitialContent
+	enrichEntry(&entry, s.templates, s.fields)
+	return entry
+}
+
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
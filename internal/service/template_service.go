@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/parser"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TemplateSourceStatic  = "static"
+	TemplateSourceLearned = "learned"
+)
+
+type TemplateService interface {
+	GetTemplates(ctx context.Context, source string) (*dto.TemplateListResponse, error)
+}
+
+type templateService struct {
+	matcher parser.TemplateMatcher
+	miner   parser.TemplateMiner
+}
+
+func NewTemplateService(matcher parser.TemplateMatcher, miner parser.TemplateMiner) TemplateService {
+	return &templateService{
+		matcher: matcher,
+		miner:   miner,
+	}
+}
+
+// GetTemplates lists static and learned templates; source filters to one of them when not empty.
+func (s *templateService) GetTemplates(ctx context.Context, source string) (*dto.TemplateListResponse, error) {
+	if source != "" && source != TemplateSourceStatic && source != TemplateSourceLearned {
+		return nil, fmt.Errorf("invalid source: %s", source)
+	}
+
+	resp := &dto.TemplateListResponse{Templates: make([]dto.TemplateInfo, 0)}
+	if source == "" || source == TemplateSourceStatic {
+		for _, t := range s.matcher.Templates() {
+			resp.Templates = append(resp.Templates, dto.TemplateInfo{
+				EventID:  t.EventID,
+				Template: t.Template,
+				Source:   TemplateSourceStatic,
+			})
+		}
+	}
+	if source == "" || source == TemplateSourceLearned {
+		for _, t := range s.miner.Templates() {
+			size := t.Size
+			resp.Templates = append(resp.Templates, dto.TemplateInfo{
+				EventID:  t.EventID,
+				Template: t.Template,
+				Source:   TemplateSourceLearned,
+				Size:     &size,
+			})
+		}
+	}
+
+	log.Info().Str("source", source).Int("count", len(resp.Templates)).Msg("Getting templates")
+	return resp, nil
+}
@@ -0,0 +1,131 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "datakat"
+
+// Metrics are the Prometheus collectors of the pipeline, exported on GET /metrics. Components that
+// already keep their own counters register a collector reading them on Registry instead.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	// Producer, observed once per processing cycle
+	ProducerCycleDuration prometheus.Histogram
+	ProducerCycleFiles    prometheus.Histogram
+	ProducerCycleLines    prometheus.Histogram
+	ProducerCycleBytes    prometheus.Histogram
+	ProducerEntries       *prometheus.CounterVec // result: sent, failed
+
+	// Consumer
+	ConsumerLag      *prometheus.GaugeVec   // partition
+	ConsumerMessages *prometheus.CounterVec // result: stored, dead_lettered, skipped
+
+	TimescaleCopyDuration *prometheus.HistogramVec // table, result: ok, error
+
+	LLMRequestDuration *prometheus.HistogramVec // result: ok, error
+	NLVQueryDuration   *prometheus.HistogramVec // result_type: timeseries, log_list, error...
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+
+		ProducerCycleDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "producer",
+			Name:      "cycle_duration_seconds",
+			Help:      "Time a log processing cycle took.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8), // 10ms to ~2.7m
+		}),
+		ProducerCycleFiles: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "producer",
+			Name:      "cycle_files",
+			Help:      "Log files looked at in a processing cycle.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+		}),
+		ProducerCycleLines: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "producer",
+			Name:      "cycle_lines",
+			Help:      "Log lines read in a processing cycle.",
+			Buckets:   prometheus.ExponentialBuckets(10, 10, 7),
+		}),
+		ProducerCycleBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "producer",
+			Name:      "cycle_bytes",
+			Help:      "Bytes of log files read in a processing cycle, decompressed.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 8, 8), // 1KB to 2GB
+		}),
+		ProducerEntries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "producer",
+			Name:      "entries_total",
+			Help:      "Log entries the producer sent to the queue, by whether the queue acknowledged them.",
+		}, []string{"result"}),
+
+		ConsumerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "lag_messages",
+			Help:      "Messages of a partition not committed yet, as of the last fetch or commit.",
+		}, []string{"partition"}),
+		ConsumerMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "messages_total",
+			Help:      "Messages the consumer committed, by whether they were stored, moved to the dead-letter topic or skipped.",
+		}, []string{"result"}),
+
+		TimescaleCopyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "timescaledb",
+			Name:      "copy_duration_seconds",
+			Help:      "Time a CopyFrom into TimescaleDB took.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"table", "result"}),
+
+		LLMRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "llm",
+			Name:      "request_duration_seconds",
+			Help:      "Time an LLM analysis took, including parsing its answer.",
+			Buckets:   prometheus.ExponentialBuckets(0.25, 2, 9), // 250ms to 64s
+		}, []string{"result"}),
+		NLVQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "nlv",
+			Name:      "query_duration_seconds",
+			Help:      "Time a natural language query took, by the type of its result.",
+			Buckets:   prometheus.ExponentialBuckets(0.25, 2, 9),
+		}, []string{"result_type"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.ProducerCycleDuration,
+		m.ProducerCycleFiles,
+		m.ProducerCycleLines,
+		m.ProducerCycleBytes,
+		m.ProducerEntries,
+		m.ConsumerLag,
+		m.ConsumerMessages,
+		m.TimescaleCopyDuration,
+		m.LLMRequestDuration,
+		m.NLVQueryDuration,
+	)
+	return m
+}
+
+// Result is the result label of an operation that returned err.
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
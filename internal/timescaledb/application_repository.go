@@ -0,0 +1,148 @@
+package timescaledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var applicationColumnsSQL = fmt.Sprintf(`a.id, a.status, a.start_time, a.end_time, a.exit_code, COALESCE(a.diagnostics, ''), a.first_seen, a.last_seen,
+	(SELECT COUNT(*) FROM %s c WHERE c.application_id = a.id)`, containersTableName)
+
+type timescaleApplicationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTimescaleApplicationRepository(pool *pgxpool.Pool) (repository.ApplicationRepository, error) {
+	if pool == nil {
+		return nil, errors.New("TimescaleDB connection pool is required for ApplicationRepository")
+	}
+	return &timescaleApplicationRepository{pool: pool}, nil
+}
+
+func (r *timescaleApplicationRepository) ListApplications(ctx context.Context, req dto.ApplicationSearchRequest) (*dto.ApplicationSearchResponse, error) {
+	whereSQL := "a.last_seen >= $1 AND a.first_seen <= $2"
+	args := []interface{}{req.StartTime, req.EndTime}
+	if req.Status != "" {
+		whereSQL += " AND a.status = $3"
+		args = append(args, req.Status)
+	}
+
+	resp := &dto.ApplicationSearchResponse{
+		Applications: make([]model.Application, 0),
+		Page:         req.Page,
+		Size:         req.Size,
+	}
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s a WHERE %s", applicationsTableName, whereSQL)
+	if err := r.pool.QueryRow(ctx, countSQL, args...).Scan(&resp.TotalCount); err != nil {
+		log.Error().Err(err).Str("query", countSQL).Msg("Failed to count applications")
+		return nil, fmt.Errorf("failed to count applications: %w", err)
+	}
+
+	querySQL := fmt.Sprintf("SELECT %s FROM %s a WHERE %s ORDER BY a.first_seen DESC, a.id LIMIT $%d OFFSET $%d",
+		applicationColumnsSQL, applicationsTableName, whereSQL, len(args)+1, len(args)+2)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.pool.Query(ctx, querySQL, args...)
+	if err != nil {
+		log.Error().Err(err).Str("query", querySQL).Msg("Failed to query applications")
+		return nil, fmt.Errorf("failed to query applications: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		app, err := scanApplication(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan application row")
+			continue
+		}
+		resp.Applications = append(resp.Applications, *app)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Msg("Error iterating application rows")
+		return nil, fmt.Errorf("failed iterating application results: %w", err)
+	}
+	return resp, nil
+}
+
+func (r *timescaleApplicationRepository) GetApplication(ctx context.Context, id string) (*model.Application, error) {
+	querySQL := fmt.Sprintf("SELECT %s FROM %s a WHERE a.id = $1", applicationColumnsSQL, applicationsTableName)
+	app, err := scanApplication(r.pool.QueryRow(ctx, querySQL, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrApplicationNotFound
+		}
+		log.Error().Err(err).Str("application", id).Msg("Failed to get application")
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+
+	if app.Attempts, err = r.getAttempts(ctx, id); err != nil {
+		return nil, err
+	}
+	if app.Containers, err = r.getContainers(ctx, id); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func (r *timescaleApplicationRepository) getAttempts(ctx context.Context, id string) ([]model.ApplicationAttempt, error) {
+	querySQL := fmt.Sprintf(`SELECT attempt, COALESCE(attempt_id, ''), status, start_time, end_time, exit_code, COALESCE(diagnostics, '')
+		FROM %s WHERE application_id = $1 ORDER BY attempt`, attemptsTableName)
+	rows, err := r.pool.Query(ctx, querySQL, id)
+	if err != nil {
+		log.Error().Err(err).Str("application", id).Msg("Failed to query application attempts")
+		return nil, fmt.Errorf("failed to query application attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]model.ApplicationAttempt, 0)
+	for rows.Next() {
+		var a model.ApplicationAttempt
+		if err := rows.Scan(&a.Attempt, &a.AttemptID, &a.Status, &a.StartTime, &a.EndTime, &a.ExitCode, &a.Diagnostics); err != nil {
+			log.Error().Err(err).Msg("Failed to scan application attempt row")
+			continue
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+func (r *timescaleApplicationRepository) getContainers(ctx context.Context, id string) ([]model.ContainerInfo, error) {
+	querySQL := fmt.Sprintf(`SELECT container_id, attempt, COALESCE(host, ''), state, exit_status, COALESCE(diagnostics, ''),
+		launch_time, end_time, first_seen, last_seen
+		FROM %s WHERE application_id = $1 ORDER BY container_id`, containersTableName)
+	rows, err := r.pool.Query(ctx, querySQL, id)
+	if err != nil {
+		log.Error().Err(err).Str("application", id).Msg("Failed to query application containers")
+		return nil, fmt.Errorf("failed to query application containers: %w", err)
+	}
+	defer rows.Close()
+
+	containers := make([]model.ContainerInfo, 0)
+	for rows.Next() {
+		var c model.ContainerInfo
+		if err := rows.Scan(&c.ContainerID, &c.Attempt, &c.Host, &c.State, &c.ExitStatus, &c.Diagnostics,
+			&c.LaunchTime, &c.EndTime, &c.FirstSeen, &c.LastSeen); err != nil {
+			log.Error().Err(err).Msg("Failed to scan container row")
+			continue
+		}
+		containers = append(containers, c)
+	}
+	return containers, rows.Err()
+}
+
+func scanApplication(row pgx.Row) (*model.Application, error) {
+	var app model.Application
+	err := row.Scan(&app.ID, &app.Status, &app.StartTime, &app.EndTime, &app.ExitCode, &app.Diagnostics,
+		&app.FirstSeen, &app.LastSeen, &app.ContainerCount)
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
@@ -0,0 +1,178 @@
+package timescaledb
+
+import (
+	"context"
+	"fmt"
+	"skeleton-internship-backend/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	applicationsTableName = "spark_applications"
+	attemptsTableName     = "spark_application_attempts"
+	containersTableName   = "spark_containers"
+)
+
+// ApplicationStore folds application lifecycle events into per-application records.
+// Every upsert merges with what is already stored, so events may arrive in any order and across batches.
+type ApplicationStore interface {
+	ApplyEvents(ctx context.Context, events []model.ApplicationEvent) error
+}
+
+type timescaleApplicationStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewApplicationStore(pool *pgxpool.Pool) (ApplicationStore, error) {
+	store := &timescaleApplicationStore{pool: pool}
+
+	setupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := store.ensureTables(setupCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure application lifecycle tables exist")
+		return nil, fmt.Errorf("failed ensuring application tables: %w", err)
+	}
+	return store, nil
+}
+
+func (s *timescaleApplicationStore) ensureTables(ctx context.Context) error {
+	createTablesSQL := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			id TEXT PRIMARY KEY,
+			status TEXT NOT NULL DEFAULT 'RUNNING',
+			status_attempt INT NOT NULL DEFAULT 0, -- Attempt that reported status, later attempts win
+			start_time TIMESTAMPTZ,
+			end_time TIMESTAMPTZ,
+			exit_code INT,
+			diagnostics TEXT,
+			first_seen TIMESTAMPTZ NOT NULL,
+			last_seen TIMESTAMPTZ NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS %[2]s (
+			application_id TEXT NOT NULL,
+			attempt INT NOT NULL,
+			attempt_id TEXT,
+			status TEXT NOT NULL DEFAULT 'RUNNING',
+			start_time TIMESTAMPTZ,
+			end_time TIMESTAMPTZ,
+			exit_code INT,
+			diagnostics TEXT,
+			PRIMARY KEY (application_id, attempt)
+		);
+		CREATE TABLE IF NOT EXISTS %[3]s (
+			container_id TEXT PRIMARY KEY,
+			application_id TEXT NOT NULL,
+			attempt INT NOT NULL DEFAULT 0,
+			host TEXT,
+			state TEXT NOT NULL DEFAULT 'RUNNING',
+			exit_status INT,
+			diagnostics TEXT,
+			launch_time TIMESTAMPTZ,
+			end_time TIMESTAMPTZ,
+			first_seen TIMESTAMPTZ NOT NULL,
+			last_seen TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_%[3]s_application ON %[3]s (application_id);`,
+		applicationsTableName, attemptsTableName, containersTableName)
+	if _, err := s.pool.Exec(ctx, createTablesSQL); err != nil {
+		return fmt.Errorf("failed to create application tables: %w", err)
+	}
+	log.Info().Msg("Ensured application lifecycle tables exist.")
+	return nil
+}
+
+var (
+	upsertApplicationSQL = fmt.Sprintf(`
+		INSERT INTO %[1]s AS a (id, status, status_attempt, start_time, end_time, exit_code, diagnostics, first_seen, last_seen)
+		VALUES ($1, COALESCE(NULLIF($2, ''), 'RUNNING'), $3, $4, $5, $6, NULLIF($7, ''), $8, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			status = CASE WHEN $2 <> '' AND EXCLUDED.status_attempt >= a.status_attempt THEN EXCLUDED.status ELSE a.status END,
+			exit_code = CASE WHEN $2 <> '' AND EXCLUDED.status_attempt >= a.status_attempt THEN COALESCE(EXCLUDED.exit_code, a.exit_code) ELSE a.exit_code END,
+			diagnostics = CASE WHEN $2 <> '' AND EXCLUDED.status_attempt >= a.status_attempt THEN COALESCE(EXCLUDED.diagnostics, a.diagnostics) ELSE a.diagnostics END,
+			status_attempt = CASE WHEN $2 <> '' THEN GREATEST(a.status_attempt, EXCLUDED.status_attempt) ELSE a.status_attempt END,
+			start_time = LEAST(a.start_time, EXCLUDED.start_time),
+			end_time = GREATEST(a.end_time, EXCLUDED.end_time),
+			first_seen = LEAST(a.first_seen, EXCLUDED.first_seen),
+			last_seen = GREATEST(a.last_seen, EXCLUDED.last_seen);`, applicationsTableName)
+
+	upsertAttemptSQL = fmt.Sprintf(`
+		INSERT INTO %[1]s AS t (application_id, attempt, attempt_id, status, start_time, end_time, exit_code, diagnostics)
+		VALUES ($1, $2, NULLIF($3, ''), COALESCE(NULLIF($4, ''), 'RUNNING'), $5, $6, $7, NULLIF($8, ''))
+		ON CONFLICT (application_id, attempt) DO UPDATE SET
+			attempt_id = COALESCE(EXCLUDED.attempt_id, t.attempt_id),
+			status = CASE WHEN $4 <> '' THEN EXCLUDED.status ELSE t.status END,
+			start_time = LEAST(t.start_time, EXCLUDED.start_time),
+			end_time = GREATEST(t.end_time, EXCLUDED.end_time),
+			exit_code = COALESCE(EXCLUDED.exit_code, t.exit_code),
+			diagnostics = COALESCE(EXCLUDED.diagnostics, t.diagnostics);`, attemptsTableName)
+
+	upsertContainerSQL = fmt.Sprintf(`
+		INSERT INTO %[1]s AS c (container_id, application_id, attempt, host, state, exit_status, diagnostics, launch_time, end_time, first_seen, last_seen)
+		VALUES ($1, $2, $3, NULLIF($4, ''), COALESCE(NULLIF($5, ''), 'RUNNING'), $6, NULLIF($7, ''), $8, $9, $10, $10)
+		ON CONFLICT (container_id) DO UPDATE SET
+			host = COALESCE(EXCLUDED.host, c.host),
+			state = CASE WHEN $5 <> '' THEN EXCLUDED.state ELSE c.state END,
+			exit_status = COALESCE(EXCLUDED.exit_status, c.exit_status),
+			diagnostics = COALESCE(EXCLUDED.diagnostics, c.diagnostics),
+			launch_time = LEAST(c.launch_time, EXCLUDED.launch_time),
+			end_time = GREATEST(c.end_time, EXCLUDED.end_time),
+			first_seen = LEAST(c.first_seen, EXCLUDED.first_seen),
+			last_seen = GREATEST(c.last_seen, EXCLUDED.last_seen);`, containersTableName)
+)
+
+func (s *timescaleApplicationStore) ApplyEvents(ctx context.Context, events []model.ApplicationEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		eventTime := e.Time
+		var startTime, endTime *time.Time
+		var appStatus, appDiagnostics string
+		var appExitCode *int
+		statusAttempt := 0
+		switch e.Kind {
+		case model.AppEventAttemptStarted:
+			startTime = &eventTime
+		case model.AppEventFinished:
+			endTime = &eventTime
+			appStatus, appExitCode, appDiagnostics = e.Status, e.ExitCode, e.Diagnostics
+			statusAttempt = e.Attempt
+		}
+		batch.Queue(upsertApplicationSQL, e.Application, appStatus, statusAttempt, startTime, endTime, appExitCode, appDiagnostics, e.Time)
+
+		if e.Attempt > 0 && (e.Kind == model.AppEventAttemptStarted || e.Kind == model.AppEventFinished) {
+			batch.Queue(upsertAttemptSQL, e.Application, e.Attempt, e.AttemptID, appStatus, startTime, endTime, e.ExitCode, e.Diagnostics)
+		}
+
+		if e.ContainerID != "" {
+			var launchTime, containerEnd *time.Time
+			var containerState string
+			switch e.Kind {
+			case model.AppEventContainerLaunched:
+				launchTime = &eventTime
+			case model.AppEventContainerCompleted:
+				containerEnd = &eventTime
+				containerState = e.Status
+			}
+			batch.Queue(upsertContainerSQL, e.ContainerID, e.Application, e.Attempt, e.Host, containerState,
+				e.ExitCode, e.Diagnostics, launchTime, containerEnd, e.Time)
+		}
+	}
+
+	results := s.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			log.Error().Err(err).Msg("Failed to apply application lifecycle event")
+			return fmt.Errorf("timescaledb application upsert failed: %w", err)
+		}
+	}
+	log.Debug().Int("events", len(events)).Msg("Applied application lifecycle events")
+	return nil
+}
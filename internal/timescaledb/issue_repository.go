@@ -0,0 +1,135 @@
+package timescaledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"skeleton-internship-backend/internal/dto"
+	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/repository"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const issueColumnsSQL = `id, fingerprint, title, COALESCE(exception_class, ''), COALESCE(component, ''), COALESCE(level, ''),
+	COALESCE(event_id, ''), first_seen, last_seen, total_count, applications, COALESCE(sample_raw_log, '')`
+
+type timescaleIssueRepository struct {
+	pool       *pgxpool.Pool
+	issueTable string
+}
+
+func NewTimescaleIssueRepository(pool *pgxpool.Pool) (repository.IssueRepository, error) {
+	if pool == nil {
+		return nil, errors.New("TimescaleDB connection pool is required for IssueRepository")
+	}
+	return &timescaleIssueRepository{
+		pool:       pool,
+		issueTable: issuesTableName,
+	}, nil
+}
+
+func (r *timescaleIssueRepository) ListIssues(ctx context.Context, req dto.IssueListRequest) (*dto.IssueListResponse, error) {
+	whereClauses := []string{"TRUE"}
+	args := []interface{}{}
+	argCounter := 1
+
+	if !req.StartTime.IsZero() {
+		whereClauses = append(whereClauses, fmt.Sprintf("last_seen >= $%d", argCounter))
+		args = append(args, req.StartTime)
+		argCounter++
+	}
+	if !req.EndTime.IsZero() {
+		whereClauses = append(whereClauses, fmt.Sprintf("first_seen <= $%d", argCounter))
+		args = append(args, req.EndTime)
+		argCounter++
+	}
+	if len(req.Applications) > 0 {
+		whereClauses = append(whereClauses, fmt.Sprintf("applications && $%d::text[]", argCounter))
+		args = append(args, req.Applications)
+		argCounter++
+	}
+	if req.Query != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("(title ILIKE $%d OR exception_class ILIKE $%d)", argCounter, argCounter))
+		args = append(args, "%"+req.Query+"%")
+		argCounter++
+	}
+	whereSQL := strings.Join(whereClauses, " AND ")
+
+	resp := &dto.IssueListResponse{
+		Issues: make([]model.Issue, 0),
+		Page:   req.Page,
+		Size:   req.Size,
+	}
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", r.issueTable, whereSQL)
+	if err := r.pool.QueryRow(ctx, countSQL, args...).Scan(&resp.TotalCount); err != nil {
+		log.Error().Err(err).Str("query", countSQL).Msg("Failed to count issues")
+		return nil, fmt.Errorf("failed to count issues: %w", err)
+	}
+
+	sortColumn, ok := map[string]string{
+		"last_seen":  "last_seen",
+		"first_seen": "first_seen",
+		"count":      "total_count",
+	}[req.SortBy]
+	if !ok {
+		sortColumn = "last_seen"
+	}
+	sortOrder := "DESC"
+	if req.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+
+	querySQL := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s %s, id DESC LIMIT $%d OFFSET $%d",
+		issueColumnsSQL, r.issueTable, whereSQL, sortColumn, sortOrder, argCounter, argCounter+1)
+	args = append(args, req.Size, (req.Page-1)*req.Size)
+
+	rows, err := r.pool.Query(ctx, querySQL, args...)
+	if err != nil {
+		log.Error().Err(err).Str("query", querySQL).Msg("Failed to query issues")
+		return nil, fmt.Errorf("failed to query issues: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan issue row")
+			continue
+		}
+		resp.Issues = append(resp.Issues, *issue)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Msg("Error iterating issue rows")
+		return nil, fmt.Errorf("failed iterating issue results: %w", err)
+	}
+	return resp, nil
+}
+
+func (r *timescaleIssueRepository) GetIssue(ctx context.Context, id int64) (*model.Issue, error) {
+	querySQL := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", issueColumnsSQL, r.issueTable)
+	issue, err := scanIssue(r.pool.QueryRow(ctx, querySQL, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrIssueNotFound
+		}
+		log.Error().Err(err).Int64("issue_id", id).Msg("Failed to get issue")
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	return issue, nil
+}
+
+func scanIssue(row pgx.Row) (*model.Issue, error) {
+	var issue model.Issue
+	err := row.Scan(
+		&issue.ID, &issue.Fingerprint, &issue.Title, &issue.ExceptionClass, &issue.Component, &issue.Level,
+		&issue.EventID, &issue.FirstSeen, &issue.LastSeen, &issue.Count, &issue.Applications, &issue.SampleRawLog,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
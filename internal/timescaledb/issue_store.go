@@ -0,0 +1,161 @@
+package timescaledb
+
+import (
+	"context"
+	"fmt"
+	"skeleton-internship-backend/internal/model"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	issuesTableName      = "error_issues"
+	occurrencesTableName = "error_issue_occurrences"
+
+	// occurrenceRetention is how long the IDs of counted occurrences are kept, well beyond how late
+	// Kafka redelivers a message.
+	occurrenceRetention  = 7 * 24 * time.Hour
+	occurrencePruneEvery = time.Hour
+)
+
+// IssueStore folds batches of error occurrences into the issues table.
+type IssueStore interface {
+	// UpsertIssues merges each issue into the row with the same fingerprint; Count is the number of new
+	// occurrences, of which those in OccurrenceIDs are only counted if they were not upserted before.
+	UpsertIssues(ctx context.Context, issues []model.Issue) error
+}
+
+type timescaleIssueStore struct {
+	pool                 *pgxpool.Pool
+	tableName            string
+	occurrencesTableName string
+	lastPrune            atomic.Int64 // Unix nanoseconds of the last pruning of old occurrences
+}
+
+func NewIssueStore(pool *pgxpool.Pool) (IssueStore, error) {
+	store := &timescaleIssueStore{
+		pool:                 pool,
+		tableName:            issuesTableName,
+		occurrencesTableName: occurrencesTableName,
+	}
+
+	setupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := store.ensureTable(setupCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure issues table exists")
+		return nil, fmt.Errorf("failed ensuring issues table: %w", err)
+	}
+	return store, nil
+}
+
+func (s *timescaleIssueStore) ensureTable(ctx context.Context) error {
+	// A plain table rather than a hypertable: one row per issue, updated in place
+	createTableSQL := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			fingerprint TEXT NOT NULL UNIQUE,
+			title TEXT NOT NULL,
+			exception_class TEXT,
+			component TEXT,
+			level TEXT,
+			event_id TEXT,
+			first_seen TIMESTAMPTZ NOT NULL,
+			last_seen TIMESTAMPTZ NOT NULL,
+			total_count BIGINT NOT NULL DEFAULT 0,
+			applications TEXT[] NOT NULL DEFAULT '{}',
+			sample_raw_log TEXT
+		);`, s.tableName)
+	if _, err := s.pool.Exec(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.tableName, err)
+	}
+
+	createOccurrencesSQL := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			fingerprint TEXT NOT NULL,
+			entry_id TEXT NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (fingerprint, entry_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_%[1]s_recorded_at ON %[1]s (recorded_at);`, s.occurrencesTableName)
+	if _, err := s.pool.Exec(ctx, createOccurrencesSQL); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.occurrencesTableName, err)
+	}
+
+	indexSQL := fmt.Sprintf(`
+        CREATE INDEX IF NOT EXISTS idx_%s_last_seen ON %s (last_seen DESC);
+        CREATE INDEX IF NOT EXISTS idx_%s_applications ON %s USING GIN (applications);
+    `, s.tableName, s.tableName, s.tableName, s.tableName)
+	if _, err := s.pool.Exec(ctx, indexSQL); err != nil {
+		log.Warn().Err(err).Msg("Failed to create indexes on issues table (continuing)")
+	}
+	log.Info().Str("table", s.tableName).Msg("Ensured issues table exists.")
+	return nil
+}
+
+func (s *timescaleIssueStore) UpsertIssues(ctx context.Context, issues []model.Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	// Lock rows in a consistent order so concurrent consumers cannot deadlock each other
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Fingerprint < issues[j].Fingerprint })
+
+	// Occurrences with an ID are recorded first and only those not recorded yet are counted, so
+	// upserting a batch again leaves the count as it is
+	upsertSQL := fmt.Sprintf(`
+		WITH new_occurrences AS (
+			INSERT INTO %[2]s (fingerprint, entry_id)
+			SELECT $1, unnest($12::text[])
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
+		INSERT INTO %[1]s (fingerprint, title, exception_class, component, level, event_id, first_seen, last_seen, total_count, applications, sample_raw_log)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7, $8, $9::bigint + (SELECT count(*) FROM new_occurrences), $10, $11)
+		ON CONFLICT (fingerprint) DO UPDATE SET
+			first_seen = LEAST(%[1]s.first_seen, EXCLUDED.first_seen),
+			last_seen = GREATEST(%[1]s.last_seen, EXCLUDED.last_seen),
+			total_count = %[1]s.total_count + EXCLUDED.total_count,
+			applications = ARRAY(SELECT DISTINCT unnest(%[1]s.applications || EXCLUDED.applications) ORDER BY 1),
+			sample_raw_log = CASE WHEN EXCLUDED.last_seen >= %[1]s.last_seen THEN EXCLUDED.sample_raw_log ELSE %[1]s.sample_raw_log END;`,
+		s.tableName, s.occurrencesTableName)
+
+	batch := &pgx.Batch{}
+	for _, issue := range issues {
+		batch.Queue(upsertSQL,
+			issue.Fingerprint, issue.Title, issue.ExceptionClass, issue.Component, issue.Level, issue.EventID,
+			issue.FirstSeen, issue.LastSeen, issue.Count-int64(len(issue.OccurrenceIDs)), issue.Applications, issue.SampleRawLog,
+			issue.OccurrenceIDs)
+	}
+
+	results := s.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range issues {
+		if _, err := results.Exec(); err != nil {
+			log.Error().Err(err).Msg("Failed to upsert issue into TimescaleDB")
+			return fmt.Errorf("timescaledb issue upsert failed: %w", err)
+		}
+	}
+	log.Debug().Int("count", len(issues)).Msg("Successfully upserted issues into TimescaleDB")
+	s.pruneOccurrences(ctx)
+	return nil
+}
+
+// pruneOccurrences deletes the IDs of occurrences past occurrenceRetention, at most every occurrencePruneEvery.
+func (s *timescaleIssueStore) pruneOccurrences(ctx context.Context) {
+	last := s.lastPrune.Load()
+	now := time.Now()
+	if now.Sub(time.Unix(0, last)) < occurrencePruneEvery || !s.lastPrune.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	pruneSQL := fmt.Sprintf(`DELETE FROM %s WHERE recorded_at < $1;`, s.occurrencesTableName)
+	tag, err := s.pool.Exec(ctx, pruneSQL, now.Add(-occurrenceRetention))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to prune old issue occurrences (continuing)")
+		return
+	}
+	log.Debug().Int64("deleted", tag.RowsAffected()).Msg("Pruned old issue occurrences")
+}
@@ -69,10 +69,15 @@ func (r *timescaleMetricRepository) GetSummaryMetrics(ctx context.Context, req d
 
 func (r *timescaleMetricRepository) GetTimeseriesMetrics(ctx context.Context, req dto.MetricTimeseriesRequest) (*dto.MetricTimeseriesResponse, error) {
 	allowedGroupBy := map[string]string{
-		"level":       "tags->>'level'",
-		"component":   "tags->>'component'",
-		"error_key":   "tags->>'error_key'",
-		"application": "application",
+		"level":           "tags->>'level'",
+		"component":       "tags->>'component'",
+		"error_key":       "tags->>'error_key'",
+		"event_id":        "tags->>'event_id'",
+		"exception_class": "tags->>'exception_class'",
+		"root_cause":      "tags->>'root_cause'",
+		"container_id":    "tags->>'container_id'",
+		"attempt":         "tags->>'attempt'",
+		"application":     "application",
 	}
 	groupByTag := req.GroupBy
 	groupBySQL, ok := allowedGroupBy[req.GroupBy]
@@ -224,41 +229,17 @@ func (r *timescaleMetricRepository) GetTimeseriesMetrics(ctx context.Context, re
 	return response, nil
 }
 
-func (r *timescaleMetricRepository) GetDistinctApplications(ctx context.Context, req dto.ApplicationListRequest) (*dto.ApplicationListResponse, error) {
-	querySQL := fmt.Sprintf("SELECT DISTINCT application FROM %s WHERE time >= $1 AND time < $2 ORDER BY application", r.eventTable)
-	args := []interface{}{req.StartTime, req.EndTime}
-
-	rows, err := r.pool.Query(ctx, querySQL, args...)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to query distinct applications")
-		return nil, fmt.Errorf("failed getting applications: %w", err)
-	}
-	defer rows.Close()
-
-	apps := make([]string, 0)
-	for rows.Next() {
-		var app string
-		if err := rows.Scan(&app); err != nil {
-			log.Error().Err(err).Msg("Failed to scan application row")
-			continue
-		}
-		apps = append(apps, app)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Error().Err(err).Msg("Error iterating application rows")
-		return nil, fmt.Errorf("failed iterating application results: %w", err)
-	}
-
-	return &dto.ApplicationListResponse{Applications: apps}, nil
-}
-
 func (r *timescaleMetricRepository) GetDistributionMetrics(ctx context.Context, req dto.MetricDistributionRequest) (*dto.MetricDistributionResponse, error) {
 	tagColumnSQL, ok := map[string]string{
-		"level":       "tags->>'level'",
-		"component":   "tags->>'component'",
-		"error_key":   "tags->>'error_key'",
-		"application": "application",
+		"level":           "tags->>'level'",
+		"component":       "tags->>'component'",
+		"error_key":       "tags->>'error_key'",
+		"event_id":        "tags->>'event_id'",
+		"exception_class": "tags->>'exception_class'",
+		"root_cause":      "tags->>'root_cause'",
+		"container_id":    "tags->>'container_id'",
+		"attempt":         "tags->>'attempt'",
+		"application":     "application",
 	}[req.Dimension]
 
 	if !ok {
@@ -3,13 +3,16 @@ package timescaledb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"skeleton-internship-backend/config"
 	"skeleton-internship-backend/internal/model"
+	"skeleton-internship-backend/internal/telemetry"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 	"go.uber.org/fx"
@@ -22,6 +25,7 @@ type MetricStore interface {
 type timescaleMetricStore struct {
 	pool      *pgxpool.Pool
 	tableName string
+	metrics   *telemetry.Metrics
 }
 
 const (
@@ -30,9 +34,10 @@ const (
 	colMetricName         = "metric_name"
 	colApplication        = "application"
 	colTags               = "tags" // Kiểu JSONB
+	colEntryID            = "entry_id"
 )
 
-func ProvideTimescaleDBPool(lc fx.Lifecycle, cfg *config.Config) (MetricStore, *pgxpool.Pool, error) {
+func ProvideTimescaleDBPool(lc fx.Lifecycle, cfg *config.Config, metrics *telemetry.Metrics) (MetricStore, *pgxpool.Pool, error) {
 	poolConfig, err := pgxpool.ParseConfig(cfg.TimescaleDB.DSN)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to parse TimescaleDB DSN")
@@ -58,6 +63,7 @@ func ProvideTimescaleDBPool(lc fx.Lifecycle, cfg *config.Config) (MetricStore, *
 	store := &timescaleMetricStore{
 		pool:      pool,
 		tableName: metricEventsTableName,
+		metrics:   metrics,
 	}
 
 	setupCtx, cancelSetup := context.WithTimeout(context.Background(), 30*time.Second)
@@ -123,6 +129,19 @@ func (s *timescaleMetricStore) ensureHypertable(ctx context.Context) error {
 		log.Info().Str("table", s.tableName).Msg("Table is already a hypertable.")
 	}
 
+	// Events are stored once per log entry, so redelivered entries do not count twice. Events of
+	// entries without an ID, written before it was added, have a NULL entry_id and are never in conflict.
+	addEntryIDSQL := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s TEXT;`, s.tableName, colEntryID)
+	if _, err := s.pool.Exec(ctx, addEntryIDSQL); err != nil {
+		return fmt.Errorf("failed to add %s column to %s: %w", colEntryID, s.tableName, err)
+	}
+	// Unique indexes of a hypertable must include its time column
+	uniqueSQL := fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS idx_%[1]s_entry ON %[1]s (%[2]s, %[3]s, %[4]s);`,
+		s.tableName, colEntryID, colMetricName, colTime)
+	if _, err := s.pool.Exec(ctx, uniqueSQL); err != nil {
+		log.Warn().Err(err).Msg("Failed to create unique entry index on metrics table, redelivered entries may be counted twice (continuing)")
+	}
+
 	// Tạo index
 	indexSQL := fmt.Sprintf(`
         CREATE INDEX IF NOT EXISTS idx_%s_name_app_time ON %s (metric_name, application, time DESC);
@@ -138,13 +157,14 @@ func (s *timescaleMetricStore) ensureHypertable(ctx context.Context) error {
 	return nil
 }
 
-// StoreMetricEvents lưu các sự kiện metric
+// StoreMetricEvents lưu các sự kiện metric. Events are copied into a temporary table first, since COPY
+// cannot skip the events of entries that were stored before.
 func (s *timescaleMetricStore) StoreMetricEvents(ctx context.Context, events []model.MetricEvent) error {
 	if len(events) == 0 {
 		return nil
 	}
 
-	columns := []string{colTime, colMetricName, colApplication, colTags}
+	columns := []string{colTime, colMetricName, colApplication, colTags, colEntryID}
 
 	source := pgx.CopyFromSlice(len(events), func(i int) ([]interface{}, error) {
 		e := events[i]
@@ -154,23 +174,64 @@ func (s *timescaleMetricStore) StoreMetricEvents(ctx context.Context, events []m
 			log.Error().Err(err).Interface("tags", e.Tags).Msg("Failed to marshal metric tags to JSON, inserting null")
 			tagsJSON = nil // Sử dụng giá trị null của SQL
 		}
-		return []interface{}{e.Time, e.MetricName, e.Application, tagsJSON}, nil
+		var entryID *string
+		if e.EntryID != "" {
+			entryID = &e.EntryID
+		}
+		return []interface{}{e.Time, e.MetricName, e.Application, tagsJSON, entryID}, nil
 	})
 
-	copyCount, err := s.pool.CopyFrom(ctx, pgx.Identifier{s.tableName}, columns, source)
+	start := time.Now()
+	inserted, err := s.copyNewEvents(ctx, columns, source)
+	s.metrics.TimescaleCopyDuration.WithLabelValues(s.tableName, telemetry.Result(err)).Observe(time.Since(start).Seconds())
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to bulk insert metric events into TimescaleDB")
 		return fmt.Errorf("timescaledb copyfrom failed: %w", err)
 	}
 
-	if int(copyCount) != len(events) {
-		log.Warn().Int64("inserted", copyCount).Int("expected", len(events)).Msg("TimescaleDB CopyFrom event count mismatch")
+	if int(inserted) != len(events) {
+		log.Debug().Int64("inserted", inserted).Int("events", len(events)).Msg("Skipped metric events stored before")
 	} else {
-		log.Debug().Int64("count", copyCount).Msg("Successfully inserted metric events into TimescaleDB")
+		log.Debug().Int64("count", inserted).Msg("Successfully inserted metric events into TimescaleDB")
 	}
 	return nil
 }
 
+// copyNewEvents copies the events into a temporary table and inserts those not stored yet, returning how many were inserted.
+func (s *timescaleMetricStore) copyNewEvents(ctx context.Context, columns []string, source pgx.CopyFromSource) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	stagingTable := s.tableName + "_staging"
+	createSQL := fmt.Sprintf(`CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP;`, stagingTable, s.tableName)
+	if _, err := tx.Exec(ctx, createSQL); err != nil {
+		return 0, fmt.Errorf("failed to create staging table: %w", err)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{stagingTable}, columns, source); err != nil {
+		return 0, err
+	}
+	columnList := strings.Join(columns, ", ")
+	insertSQL := fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT DO NOTHING;`, s.tableName, columnList, columnList, stagingTable)
+	tag, err := tx.Exec(ctx, insertSQL)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), tx.Commit(ctx)
+}
+
 func (s *timescaleMetricStore) Close() {
 	s.pool.Close()
 }
+
+// IsDataError reports whether TimescaleDB refused the rows themselves, with a data exception or an
+// integrity constraint violation. Any other error, like a lost connection, is worth retrying as is.
+func IsDataError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}